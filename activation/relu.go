@@ -0,0 +1,17 @@
+package activation
+
+// ReLU is the rectified linear unit function.
+func ReLU(i, j int, z float64) float64 {
+	if z > 0 {
+		return z
+	}
+	return 0
+}
+
+// ReLUPrime returns the derivative of the rectified linear unit function, taken as 0 at the origin.
+func ReLUPrime(i, j int, z float64) float64 {
+	if z > 0 {
+		return 1
+	}
+	return 0
+}
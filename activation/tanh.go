@@ -0,0 +1,16 @@
+package activation
+
+import (
+	"math"
+)
+
+// Tanh is the hyperbolic tangent function.
+func Tanh(i, j int, z float64) float64 {
+	return math.Tanh(z)
+}
+
+// TanhPrime returns the derivative of the hyperbolic tangent function.
+func TanhPrime(i, j int, z float64) float64 {
+	t := math.Tanh(z)
+	return 1 - t*t
+}
@@ -0,0 +1,108 @@
+package augment_test
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/augment"
+	"neuraldeep/network"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestShift ...
+func TestShift(t *testing.T) {
+	image := []float64{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, 9,
+	}
+	shifted, err := augment.Shift(image, 1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, shifted, []float64{
+		0, 4, 5,
+		0, 7, 8,
+		0, 0, 0,
+	})
+
+	_, err = augment.Shift([]float64{1, 2, 3}, 1, 1)
+	assert.Error(t, err, "the image must be square")
+}
+
+// TestRotate ...
+func TestRotate(t *testing.T) {
+	image := []float64{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, 9,
+	}
+	rotated, err := augment.Rotate(image, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{
+		7, 4, 1,
+		8, 5, 2,
+		9, 6, 3,
+	}
+	for i, e := range expected {
+		assert.Assert(t, math.Abs(rotated[i]-e) < 1e-9, "pixel %d: %f != %f", i, rotated[i], e)
+	}
+}
+
+// TestElastic ...
+func TestElastic(t *testing.T) {
+	image := make([]float64, 784)
+	for i := range image {
+		image[i] = float64(i % 256)
+	}
+	d1, err := augment.Elastic(image, 34, 4, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, _ := augment.Elastic(image, 34, 4, rand.New(rand.NewSource(1)))
+	assert.DeepEqual(t, d1, d2)
+	assert.Assert(t, len(d1) == 784)
+
+	// A null intensity keeps the image untouched
+	d3, _ := augment.Elastic(image, 0, 4, rand.New(rand.NewSource(1)))
+	assert.DeepEqual(t, d3, image)
+}
+
+// TestNoise ...
+func TestNoise(t *testing.T) {
+	image := []float64{0, 255, 128, 0}
+	noisy := augment.Noise(image, 50, rand.New(rand.NewSource(1)))
+	for _, v := range noisy {
+		assert.Assert(t, v >= augment.MIN_PIXEL && v <= augment.MAX_PIXEL)
+	}
+}
+
+// TestExpand ...
+func TestExpand(t *testing.T) {
+	ds := network.Dataset{
+		&network.Input{Data: make([]float64, 784), Label: network.ToLabel(3, 10)},
+		&network.Input{Data: make([]float64, 784), Label: network.ToLabel(7, 10)},
+	}
+	expanded, err := augment.ExpandShifts(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(expanded), 10)
+	assert.Equal(t, expanded[9].Label.Value, 7.)
+
+	a, err := augment.New([]string{augment.SHIFT, augment.ROTATE, augment.ELASTIC, augment.NOISE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expanded, err = augment.Expand(ds, a, 3, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(expanded), 8)
+
+	_, err = augment.New([]string{"flip"})
+	assert.Error(t, err, "unavailable transformation: flip")
+}
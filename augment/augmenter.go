@@ -0,0 +1,133 @@
+package augment
+
+import (
+	"fmt"
+	"math/rand"
+	"neuraldeep/network"
+	"strings"
+)
+
+const (
+	SHIFT   = "shift"
+	ROTATE  = "rotate"
+	ELASTIC = "elastic"
+	NOISE   = "noise"
+)
+
+//--- TYPES
+
+// Augmenter randomly distorts images, each transformation being skipped when its parameter is zero.
+// 'MaxShift' is the maximum number of pixels of a translation in each direction, 'MaxRotation' the maximum angle in degrees,
+// 'ElasticAlpha' and 'ElasticSigma' the intensity and smoothness of the elastic distortion, and 'NoiseStdDev' the standard
+// deviation of the Gaussian noise.
+type Augmenter struct {
+	MaxShift     int
+	MaxRotation  float64
+	ElasticAlpha float64
+	ElasticSigma float64
+	NoiseStdDev  float64
+}
+
+//--- METHODS
+
+// Apply returns a randomly distorted copy of the image, drawing the parameters of the transformations from 'rng'.
+func (a Augmenter) Apply(image []float64, rng *rand.Rand) (distorted []float64, err error) {
+	if a == (Augmenter{}) {
+		return append([]float64(nil), image...), nil
+	}
+	distorted = image
+	if a.ElasticAlpha > 0 {
+		if distorted, err = Elastic(distorted, a.ElasticAlpha, a.ElasticSigma, rng); err != nil {
+			return
+		}
+	}
+	if a.MaxRotation > 0 {
+		if distorted, err = Rotate(distorted, (2*rng.Float64()-1)*a.MaxRotation); err != nil {
+			return
+		}
+	}
+	if a.MaxShift > 0 {
+		dx := rng.Intn(2*a.MaxShift+1) - a.MaxShift
+		dy := rng.Intn(2*a.MaxShift+1) - a.MaxShift
+		if distorted, err = Shift(distorted, dx, dy); err != nil {
+			return
+		}
+	}
+	if a.NoiseStdDev > 0 {
+		distorted = Noise(distorted, a.NoiseStdDev, rng)
+	}
+	return
+}
+
+// Transform returns a new input holding a distorted copy of the data of 'x' and sharing its label.
+// It matches the `network.Augmentation` signature so that it can be used on the fly during training.
+// Note that it panics if the data isn't a square image.
+func (a Augmenter) Transform(x *network.Input, rng *rand.Rand) *network.Input {
+	data, err := a.Apply(x.Data, rng)
+	if err != nil {
+		panic(err)
+	}
+	return &network.Input{
+		Data:  data,
+		Label: x.Label,
+	}
+}
+
+//--- FUNCTIONS
+
+// New returns an augmenter enabling the transformations of the passed names with default settings suited to MNIST digits,
+// ie. shifts of up to 2 pixels, rotations of up to 15°, elastic distortions with α = 34 and σ = 4, and a noise of 8% of the pixel range.
+func New(names []string) (a Augmenter, err error) {
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case SHIFT:
+			a.MaxShift = 2
+		case ROTATE:
+			a.MaxRotation = 15
+		case ELASTIC:
+			a.ElasticAlpha = 34
+			a.ElasticSigma = 4
+		case NOISE:
+			a.NoiseStdDev = 0.08 * (MAX_PIXEL - MIN_PIXEL)
+		default:
+			err = fmt.Errorf("unavailable transformation: %s", name)
+			return
+		}
+	}
+	return
+}
+
+// Expand returns the original dataset followed by 'copies' distorted versions of each of its inputs.
+func Expand(ds network.Dataset, a Augmenter, copies int, rng *rand.Rand) (expanded network.Dataset, err error) {
+	expanded = make(network.Dataset, 0, len(ds)*(copies+1))
+	expanded = append(expanded, ds...)
+	for c := 0; c < copies; c++ {
+		for _, input := range ds {
+			data, e := a.Apply(input.Data, rng)
+			if e != nil {
+				err = e
+				return
+			}
+			expanded = append(expanded, &network.Input{Data: data, Label: input.Label})
+		}
+	}
+	return
+}
+
+// ExpandShifts reproduces Michael Nielsen's expand_mnist.py by adding the four one-pixel shifts (up, down, left and right)
+// of each input to the dataset, eg. turning the 50,000 MNIST training images into 250,000.
+func ExpandShifts(ds network.Dataset) (expanded network.Dataset, err error) {
+	expanded = make(network.Dataset, 0, len(ds)*5)
+	for _, input := range ds {
+		expanded = append(expanded, input)
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			data, e := Shift(input.Data, d[0], d[1])
+			if e != nil {
+				err = e
+				return
+			}
+			expanded = append(expanded, &network.Input{Data: data, Label: input.Label})
+		}
+	}
+	return
+}
@@ -0,0 +1,158 @@
+package augment
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// A package to distort the images of a dataset in order to artificially expand it, as Michael Nielsen does in expand_mnist.py.
+// Images are expected to be square and flattened row by row, eg. the 784 pixels of an MNIST digit,
+// with values in the [MIN_PIXEL, MAX_PIXEL] range.
+
+const (
+	MIN_PIXEL = 0.
+	MAX_PIXEL = 255.
+)
+
+//--- FUNCTIONS
+
+// Shift translates the image by 'dx' pixels to the right and 'dy' pixels to the bottom, filling the uncovered area with blank pixels.
+func Shift(image []float64, dx, dy int) ([]float64, error) {
+	side, err := sideOf(image)
+	if err != nil {
+		return nil, err
+	}
+	shifted := make([]float64, len(image))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			sx, sy := x-dx, y-dy
+			if sx >= 0 && sx < side && sy >= 0 && sy < side {
+				shifted[y*side+x] = image[sy*side+sx]
+			}
+		}
+	}
+	return shifted, nil
+}
+
+// Rotate turns the image clockwise by 'degrees' around its center, using bilinear interpolation.
+func Rotate(image []float64, degrees float64) ([]float64, error) {
+	side, err := sideOf(image)
+	if err != nil {
+		return nil, err
+	}
+	theta := degrees * math.Pi / 180
+	cos, sin := math.Cos(theta), math.Sin(theta)
+	center := float64(side-1) / 2
+	rotated := make([]float64, len(image))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			// Inverse mapping: find where the destination pixel comes from
+			rx, ry := float64(x)-center, float64(y)-center
+			sx := cos*rx + sin*ry + center
+			sy := -sin*rx + cos*ry + center
+			rotated[y*side+x] = sample(image, side, sx, sy)
+		}
+	}
+	return rotated, nil
+}
+
+// Elastic applies the elastic distortion described by Simard, Steinkraus and Platt (2003):
+// each pixel is moved by a random displacement field smoothed with a Gaussian filter of standard deviation 'sigma'
+// and scaled by the intensity 'alpha'.
+func Elastic(image []float64, alpha, sigma float64, rng *rand.Rand) ([]float64, error) {
+	side, err := sideOf(image)
+	if err != nil {
+		return nil, err
+	}
+	if sigma <= 0 {
+		return nil, errors.New("sigma must be positive")
+	}
+	dx := make([]float64, len(image))
+	dy := make([]float64, len(image))
+	for i := range image {
+		dx[i] = 2*rng.Float64() - 1
+		dy[i] = 2*rng.Float64() - 1
+	}
+	dx = smooth(dx, side, sigma)
+	dy = smooth(dy, side, sigma)
+	distorted := make([]float64, len(image))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			i := y*side + x
+			distorted[i] = sample(image, side, float64(x)+alpha*dx[i], float64(y)+alpha*dy[i])
+		}
+	}
+	return distorted, nil
+}
+
+// Noise adds a Gaussian noise of standard deviation 'stdDev' to each pixel, keeping the values in the pixel range.
+func Noise(image []float64, stdDev float64, rng *rand.Rand) []float64 {
+	noisy := make([]float64, len(image))
+	for i, v := range image {
+		noisy[i] = math.Max(MIN_PIXEL, math.Min(MAX_PIXEL, v+rng.NormFloat64()*stdDev))
+	}
+	return noisy
+}
+
+// utility functions
+
+func sideOf(image []float64) (int, error) {
+	side := int(math.Round(math.Sqrt(float64(len(image)))))
+	if side == 0 || side*side != len(image) {
+		return 0, errors.New("the image must be square")
+	}
+	return side, nil
+}
+
+// sample returns the bilinear interpolation of the image at the (x, y) position, outside pixels being blank.
+func sample(image []float64, side int, x, y float64) float64 {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+	at := func(i, j int) float64 {
+		if i < 0 || i >= side || j < 0 || j >= side {
+			return MIN_PIXEL
+		}
+		return image[j*side+i]
+	}
+	top := (1-fx)*at(x0, y0) + fx*at(x0+1, y0)
+	bottom := (1-fx)*at(x0, y0+1) + fx*at(x0+1, y0+1)
+	return (1-fy)*top + fy*bottom
+}
+
+// smooth convolves the field with a Gaussian kernel, separately along each axis.
+func smooth(field []float64, side int, sigma float64) []float64 {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	sum := 0.
+	for k := range kernel {
+		d := float64(k - radius)
+		kernel[k] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[k]
+	}
+	for k := range kernel {
+		kernel[k] /= sum
+	}
+	convolve := func(src []float64, horizontal bool) []float64 {
+		dst := make([]float64, len(src))
+		for y := 0; y < side; y++ {
+			for x := 0; x < side; x++ {
+				v := 0.
+				for k, w := range kernel {
+					i, j := x, y
+					if horizontal {
+						i += k - radius
+					} else {
+						j += k - radius
+					}
+					if i >= 0 && i < side && j >= 0 && j < side {
+						v += w * src[j*side+i]
+					}
+				}
+				dst[y*side+x] = v
+			}
+		}
+		return dst
+	}
+	return convolve(convolve(field, true), false)
+}
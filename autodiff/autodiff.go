@@ -0,0 +1,272 @@
+package autodiff
+
+import (
+	"math"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// A package of reverse-mode automatic differentiation over gonum matrices: each operation on a Node computes its value
+// right away and records on the tape how to propagate a gradient back to its operands, so that Backward() can then
+// compute the gradients of any result with respect to all the variables it depends on in a single reverse sweep.
+// Costs and layers can thus be written as forward code only, their derivatives (eg. SigmoidPrime) being derived from it.
+
+//--- TYPES
+
+// Node is a matrix computed on a tape. Once Backward() was called, 'Grad' holds the gradient of the result
+// with respect to it, or is nil if the result doesn't depend on it or if it isn't derived from a variable.
+type Node struct {
+	Value    *mat.Dense
+	Grad     *mat.Dense
+	tape     *Tape
+	variable bool
+	backward func(grad *mat.Dense)
+}
+
+// Tape records the nodes in the order of their computation, which is a topological order of the graph of operations.
+type Tape struct {
+	nodes []*Node
+}
+
+//--- METHODS
+
+// Backward computes the gradients of the node 'out' with respect to all the nodes of the tape it depends on.
+// By default, the gradient of 'out' is a matrix of ones, ie. the gradient of the sum of its values, but another one can be
+// passed as 'seed', eg. the gradient of a cost with respect to the output of a layer. The previous gradients are reset.
+func (t *Tape) Backward(out *Node, seed ...mat.Matrix) {
+	for _, n := range t.nodes {
+		n.Grad = nil
+	}
+	if !out.variable {
+		return
+	}
+	r, c := out.Value.Dims()
+	if len(seed) > 0 && seed[0] != nil {
+		out.Grad = mat.DenseCopyOf(seed[0])
+	} else {
+		out.Grad = mat.NewDense(r, c, nil)
+		out.Grad.Apply(func(_, _ int, _ float64) float64 { return 1 }, out.Grad)
+	}
+	for i := len(t.nodes) - 1; i >= 0; i-- {
+		if n := t.nodes[i]; n.Grad != nil && n.backward != nil {
+			n.backward(n.Grad)
+		}
+	}
+}
+
+// Constant records a matrix whose gradient isn't needed, eg. an input or a target. It shares the data of a *mat.Dense.
+func (t *Tape) Constant(m mat.Matrix) *Node {
+	return t.record(dense(m), false, nil)
+}
+
+// Len returns the number of nodes recorded on the tape.
+func (t *Tape) Len() int {
+	return len(t.nodes)
+}
+
+// Variable records a matrix whose gradient is needed, eg. a parameter. It shares the data of a *mat.Dense.
+func (t *Tape) Variable(m mat.Matrix) *Node {
+	return t.record(dense(m), true, nil)
+}
+
+func (t *Tape) record(value *mat.Dense, variable bool, backward func(grad *mat.Dense)) *Node {
+	n := &Node{Value: value, tape: t, variable: variable, backward: backward}
+	t.nodes = append(t.nodes, n)
+	return n
+}
+
+// Add returns `n + m`.
+func (n *Node) Add(m *Node) *Node {
+	var v mat.Dense
+	v.Add(n.Value, m.Value)
+	return n.binary(m, &v, func(g *mat.Dense) {
+		n.accumulate(g)
+		m.accumulate(g)
+	})
+}
+
+// AddRow adds the 1×c row 'm' to each row of 'n', eg. the biases of a layer to the weighted inputs of a mini-batch.
+func (n *Node) AddRow(m *Node) *Node {
+	var v mat.Dense
+	v.Apply(func(_, j int, x float64) float64 {
+		return x + m.Value.At(0, j)
+	}, n.Value)
+	return n.binary(m, &v, func(g *mat.Dense) {
+		n.accumulate(g)
+		m.accumulate(matrix.ColumnSums(g))
+	})
+}
+
+// AddScalar returns 'n' plus 's' to each of its values.
+func (n *Node) AddScalar(s float64) *Node {
+	return n.elementWise(func(x float64) float64 { return x + s }, func(_, _ float64) float64 { return 1 })
+}
+
+// Div returns `n / m` element-wise.
+func (n *Node) Div(m *Node) *Node {
+	var v mat.Dense
+	v.DivElem(n.Value, m.Value)
+	return n.binary(m, &v, func(g *mat.Dense) {
+		var gn, gm mat.Dense
+		gn.DivElem(g, m.Value)
+		n.accumulate(&gn)
+		gm.MulElem(&gn, &v)
+		gm.Scale(-1, &gm)
+		m.accumulate(&gm)
+	})
+}
+
+// Dot returns the matrix product `n·m`.
+func (n *Node) Dot(m *Node) *Node {
+	var v mat.Dense
+	v.Mul(n.Value, m.Value)
+	return n.binary(m, &v, func(g *mat.Dense) {
+		if n.variable {
+			var gn mat.Dense
+			gn.Mul(g, m.Value.T())
+			n.accumulate(&gn)
+		}
+		if m.variable {
+			var gm mat.Dense
+			gm.Mul(n.Value.T(), g)
+			m.accumulate(&gm)
+		}
+	})
+}
+
+// Exp applies the exponential function element-wise.
+func (n *Node) Exp() *Node {
+	return n.elementWise(math.Exp, func(_, y float64) float64 { return y })
+}
+
+// Log applies the natural logarithm element-wise.
+func (n *Node) Log() *Node {
+	return n.elementWise(math.Log, func(x, _ float64) float64 { return 1 / x })
+}
+
+// MulElem returns `n ⊙ m`, ie. the element-wise product.
+func (n *Node) MulElem(m *Node) *Node {
+	var v mat.Dense
+	v.MulElem(n.Value, m.Value)
+	return n.binary(m, &v, func(g *mat.Dense) {
+		var gn, gm mat.Dense
+		gn.MulElem(g, m.Value)
+		n.accumulate(&gn)
+		gm.MulElem(g, n.Value)
+		m.accumulate(&gm)
+	})
+}
+
+// ReLU applies the rectified linear unit function element-wise.
+func (n *Node) ReLU() *Node {
+	return n.elementWise(func(x float64) float64 {
+		return math.Max(x, 0)
+	}, func(x, _ float64) float64 {
+		if x > 0 {
+			return 1
+		}
+		return 0
+	})
+}
+
+// Scale returns `s·n`.
+func (n *Node) Scale(s float64) *Node {
+	return n.elementWise(func(x float64) float64 { return s * x }, func(_, _ float64) float64 { return s })
+}
+
+// Sigmoid applies the sigmoid function element-wise.
+func (n *Node) Sigmoid() *Node {
+	return n.elementWise(func(x float64) float64 {
+		return 1 / (1 + math.Exp(-x))
+	}, func(_, y float64) float64 {
+		return y * (1 - y)
+	})
+}
+
+// Square returns `n ⊙ n`.
+func (n *Node) Square() *Node {
+	return n.elementWise(func(x float64) float64 { return x * x }, func(x, _ float64) float64 { return 2 * x })
+}
+
+// Sub returns `n - m`.
+func (n *Node) Sub(m *Node) *Node {
+	var v mat.Dense
+	v.Sub(n.Value, m.Value)
+	return n.binary(m, &v, func(g *mat.Dense) {
+		n.accumulate(g)
+		var gm mat.Dense
+		gm.Scale(-1, g)
+		m.accumulate(&gm)
+	})
+}
+
+// Sum returns the sum of the values of 'n' as a 1×1 matrix.
+func (n *Node) Sum() *Node {
+	v := mat.NewDense(1, 1, []float64{mat.Sum(n.Value)})
+	return n.tape.record(v, n.variable, func(g *mat.Dense) {
+		r, c := n.Value.Dims()
+		s := g.At(0, 0)
+		gn := mat.NewDense(r, c, nil)
+		gn.Apply(func(_, _ int, _ float64) float64 { return s }, gn)
+		n.accumulate(gn)
+	})
+}
+
+// T returns the transpose of 'n'.
+func (n *Node) T() *Node {
+	v := mat.DenseCopyOf(n.Value.T())
+	return n.tape.record(v, n.variable, func(g *mat.Dense) {
+		n.accumulate(g.T())
+	})
+}
+
+// Tanh applies the hyperbolic tangent function element-wise.
+func (n *Node) Tanh() *Node {
+	return n.elementWise(math.Tanh, func(_, y float64) float64 { return 1 - y*y })
+}
+
+// accumulate adds 'g' to the gradient of the node, unless it isn't derived from a variable.
+func (n *Node) accumulate(g mat.Matrix) {
+	if !n.variable {
+		return
+	}
+	if n.Grad == nil {
+		n.Grad = mat.DenseCopyOf(g)
+		return
+	}
+	n.Grad.Add(n.Grad, g)
+}
+
+// binary records the result 'v' of an operation of 'n' and 'm'.
+func (n *Node) binary(m *Node, v *mat.Dense, backward func(grad *mat.Dense)) *Node {
+	return n.tape.record(v, n.variable || m.variable, backward)
+}
+
+// elementWise records the result of the function 'fn' applied to each value 'x' of 'n', 'prime' returning its derivative
+// from 'x' and from the result `y = fn(x)`, whichever is the most convenient.
+func (n *Node) elementWise(fn func(x float64) float64, prime func(x, y float64) float64) *Node {
+	var v mat.Dense
+	v.Apply(func(_, _ int, x float64) float64 { return fn(x) }, n.Value)
+	return n.tape.record(&v, n.variable, func(g *mat.Dense) {
+		var gn mat.Dense
+		gn.Apply(func(i, j int, gv float64) float64 {
+			return gv * prime(n.Value.At(i, j), v.At(i, j))
+		}, g)
+		n.accumulate(&gn)
+	})
+}
+
+//--- FUNCTIONS
+
+// NewTape ...
+func NewTape() *Tape {
+	return &Tape{}
+}
+
+func dense(m mat.Matrix) *mat.Dense {
+	if d, ok := m.(*mat.Dense); ok {
+		return d
+	}
+	return mat.DenseCopyOf(m)
+}
@@ -0,0 +1,129 @@
+package autodiff_test
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/autodiff"
+	"neuraldeep/cost"
+	"neuraldeep/gradcheck"
+	"neuraldeep/network"
+	"neuraldeep/utils/matrix"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestBackward compares the gradient of each operation with its central difference estimate.
+func TestBackward(t *testing.T) {
+	positive := func(r, c int) *mat.Dense {
+		m := mat.DenseCopyOf(matrix.Gaussian(r, c, 0, 1))
+		m.Apply(func(_, _ int, v float64) float64 { return math.Abs(v) + 0.5 }, m)
+		return m
+	}
+	for name, fn := range map[string]func(a, b, row *autodiff.Node) *autodiff.Node{
+		"Add":       func(a, b, _ *autodiff.Node) *autodiff.Node { return a.Add(b) },
+		"AddRow":    func(a, _, row *autodiff.Node) *autodiff.Node { return a.AddRow(row) },
+		"AddScalar": func(a, _, _ *autodiff.Node) *autodiff.Node { return a.AddScalar(2) },
+		"Div":       func(a, b, _ *autodiff.Node) *autodiff.Node { return a.Div(b) },
+		"Dot":       func(a, b, _ *autodiff.Node) *autodiff.Node { return a.Dot(b.T()).Dot(b) },
+		"Exp":       func(a, _, _ *autodiff.Node) *autodiff.Node { return a.Exp() },
+		"Log":       func(a, _, _ *autodiff.Node) *autodiff.Node { return a.Log() },
+		"MulElem":   func(a, b, _ *autodiff.Node) *autodiff.Node { return a.MulElem(b) },
+		"ReLU":      func(a, b, _ *autodiff.Node) *autodiff.Node { return a.Sub(b).ReLU() },
+		"Scale":     func(a, _, _ *autodiff.Node) *autodiff.Node { return a.Scale(-3) },
+		"Sigmoid":   func(a, _, _ *autodiff.Node) *autodiff.Node { return a.Sigmoid() },
+		"Square":    func(a, _, _ *autodiff.Node) *autodiff.Node { return a.Square() },
+		"Sub":       func(a, b, _ *autodiff.Node) *autodiff.Node { return a.Sub(b) },
+		"Tanh":      func(a, _, _ *autodiff.Node) *autodiff.Node { return a.Tanh() },
+		// A node used twice accumulates both gradients
+		"Reuse": func(a, b, _ *autodiff.Node) *autodiff.Node { return a.MulElem(a).Add(a.Dot(b.T()).Dot(a)) },
+	} {
+		params := []*mat.Dense{positive(3, 4), positive(3, 4), positive(1, 4)}
+		// The weights of the sum make the gradient differ from one value of the result to the other
+		weights := mat.DenseCopyOf(matrix.Gaussian(3, 4, 0, 1))
+		loss := func() (*autodiff.Tape, []*autodiff.Node, *autodiff.Node) {
+			tape := autodiff.NewTape()
+			vars := make([]*autodiff.Node, len(params))
+			for i, p := range params {
+				vars[i] = tape.Variable(p)
+			}
+			return tape, vars, fn(vars[0], vars[1], vars[2]).MulElem(tape.Constant(weights)).Sum()
+		}
+		tape, vars, out := loss()
+		tape.Backward(out)
+		for i, p := range params {
+			if vars[i].Grad == nil {
+				continue
+			}
+			err := gradcheck.CompareMatrix(p, vars[i].Grad, func() float64 {
+				_, _, out := loss()
+				return out.Value.At(0, 0)
+			})
+			assert.NilError(t, err, name)
+		}
+	}
+}
+
+// TestBackprop checks the gradients of both networks against automatic differentiation of their forward pass.
+func TestBackprop(t *testing.T) {
+	x := network.Synthetic(1, 6, 3, rand.New(rand.NewSource(1)))[0]
+	crossEntropy := func(a, y *autodiff.Node) *autodiff.Node {
+		return y.MulElem(a.Log()).Add(y.Scale(-1).AddScalar(1).MulElem(a.Scale(-1).AddScalar(1).Log())).Sum().Scale(-1)
+	}
+	quadratic := func(a, y *autodiff.Node) *autodiff.Node {
+		return a.Sub(y).Square().Sum().Scale(0.5)
+	}
+	net1, err := network.Init([]int{6, 5, 4, 3})
+	assert.NilError(t, err)
+	net2, err := network.Initial([]int{6, 5, 4, 3})
+	assert.NilError(t, err)
+	net3, err := network.Initial([]int{6, 5, 3}, cost.QuadraticCost{Name: cost.QUADRATIC_COST})
+	assert.NilError(t, err)
+	for _, tt := range []struct {
+		model interface {
+			Backprop(x *network.Input) (biasesByLayer, weightsByLayer []mat.Matrix)
+			Parameters() (biases, weights []mat.Matrix)
+		}
+		fn func(a, y *autodiff.Node) *autodiff.Node
+	}{{net1, quadratic}, {net2, crossEntropy}, {net3, quadratic}} {
+		nablaB, nablaW := tt.model.Backprop(x)
+		biases, weights := tt.model.Parameters()
+		tape := autodiff.NewTape()
+		bs, ws := make([]*autodiff.Node, len(biases)), make([]*autodiff.Node, len(weights))
+		a := tape.Constant(mat.NewDense(1, len(x.Data), x.Data))
+		for l := range weights {
+			bs[l], ws[l] = tape.Variable(biases[l]), tape.Variable(weights[l])
+			a = a.Dot(ws[l].T()).AddRow(bs[l]).Sigmoid()
+		}
+		tape.Backward(tt.fn(a, tape.Constant(x.Label.Vector.T())))
+		for l := range weights {
+			assert.Assert(t, mat.EqualApprox(bs[l].Grad, nablaB[l], 1e-12))
+			assert.Assert(t, mat.EqualApprox(ws[l].Grad, nablaW[l], 1e-12))
+		}
+	}
+}
+
+// TestCost ...
+func TestCost(t *testing.T) {
+	crossEntropy := autodiff.Cost{Name: "custom", Fn: func(a, y *autodiff.Node) *autodiff.Node {
+		return y.MulElem(a.Log()).Add(y.Scale(-1).AddScalar(1).MulElem(a.Scale(-1).AddScalar(1).Log())).Scale(-1)
+	}}
+	expected := cost.CrossEntropyCost{Name: cost.CROSS_ENTROPY}
+	z := mat.NewDense(1, 3, []float64{-1, 0.5, 2})
+	a := matrix.Apply(func(_, _ int, v float64) float64 { return 1 / (1 + math.Exp(-v)) }, z)
+	y := mat.NewVecDense(3, []float64{0, 1, 0})
+	assert.Assert(t, math.Abs(crossEntropy.Function(a, y)-expected.Function(a, y)) < 1e-12)
+	assert.Assert(t, mat.EqualApprox(crossEntropy.Delta(a, y, z), expected.Delta(a, y, z), 1e-12))
+	assert.Equal(t, crossEntropy.GetName(), "custom")
+
+	// A network trains with it as with the hand-written cost
+	net, err := network.Initial([]int{4, 6, 2}, crossEntropy)
+	assert.NilError(t, err)
+	data := network.Synthetic(40, 4, 2, rand.New(rand.NewSource(1)))
+	before := net.TotalCost(data, 0)
+	for i := 0; i < 20; i++ {
+		net.UpdateMiniBatch(data, 1, 0, len(data))
+	}
+	assert.Assert(t, net.TotalCost(data, 0) < before)
+}
@@ -0,0 +1,41 @@
+package autodiff
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+//--- TYPES
+
+// Cost is a cost function written as forward code only: 'Fn' computes the cost of the 1×n output 'a' of a network
+// for the desired 1×n output 'y', its values being summed if it isn't a scalar. It implements cost.Cost for networks
+// whose output layer is a sigmoid, like Network2, the error delta being derived automatically.
+type Cost struct {
+	Name string
+	Fn   func(a, y *Node) *Node
+}
+
+//--- METHODS
+
+// Delta returns the error delta `𝛿C_x / 𝛿z` of the output layer, computed by automatic differentiation from 'z'.
+// As for cost.CrossEntropyCost, the parameter 'a' isn't used, being `σ(z)`.
+func (c Cost) Delta(a mat.Matrix, y mat.Vector, z mat.Matrix) mat.Matrix {
+	t := NewTape()
+	zn := t.Variable(z)
+	t.Backward(c.Fn(zn.Sigmoid(), t.Constant(y.T())).Sum())
+	if zn.Grad == nil {
+		rows, cols := z.Dims()
+		return mat.NewDense(rows, cols, nil)
+	}
+	return zn.Grad
+}
+
+// Function returns the cost associated with an output 'a' and desired output 'y'.
+func (c Cost) Function(a mat.Matrix, y mat.Vector) float64 {
+	t := NewTape()
+	return mat.Sum(c.Fn(t.Constant(a), t.Constant(y.T())).Value)
+}
+
+// GetName ...
+func (c Cost) GetName() string {
+	return c.Name
+}
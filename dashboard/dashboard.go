@@ -0,0 +1,173 @@
+package dashboard
+
+import (
+	_ "embed" // embed the web page
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"neuraldeep/network"
+	"sync"
+	"time"
+)
+
+// A live dashboard of a training: the web page at `/` subscribes to the Server-Sent Events stream at `/events`,
+// which replays the epochs already done then pushes each new one as soon as SGD() publishes it.
+// The events are named `epoch`, with a JSON network.Epoch as data, and `done` once the training is over.
+
+const subscriberBuffer = 64
+
+//go:embed index.html
+var page string
+
+var pageTemplate = template.Must(template.New("dashboard").Parse(page))
+
+//--- TYPES
+
+// Dashboard collects the epochs of a training and streams them to the connected browsers.
+type Dashboard struct {
+	Title       string
+	mu          sync.Mutex
+	history     []network.Epoch
+	done        bool
+	subscribers map[chan event]bool
+}
+
+type event struct {
+	name string
+	data []byte
+}
+
+//--- METHODS
+
+// Finish tells the connected browsers that the training is over.
+func (d *Dashboard) Finish() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.done = true
+	d.broadcast(event{name: "done", data: []byte("{}")})
+}
+
+// Handler returns the routes of the dashboard.
+func (d *Dashboard) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", d.index)
+	mux.HandleFunc("GET /events", d.events)
+	mux.HandleFunc("GET /history", d.historyJSON)
+	return mux
+}
+
+// History returns a copy of the epochs published so far.
+func (d *Dashboard) History() []network.Epoch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]network.Epoch(nil), d.history...)
+}
+
+// ListenAndServe listens on the TCP network address 'addr', eg. ":8080", until the server fails.
+func (d *Dashboard) ListenAndServe(addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           d.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// Publish records the epoch and pushes it to the connected browsers. Its signature makes it a network.EpochHook.
+func (d *Dashboard) Publish(e network.Epoch) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.history = append(d.history, e)
+	d.broadcast(event{name: "epoch", data: data})
+}
+
+// broadcast sends the event to every subscriber, dropping the ones too slow to keep up:
+// their browser reconnects and gets the whole history replayed. The lock must be held.
+func (d *Dashboard) broadcast(e event) {
+	for ch := range d.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(d.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (d *Dashboard) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Subscribe and copy the history at once so that no epoch is missed or sent twice,
+	// but replay it without the lock not to slow the training down
+	ch := make(chan event, subscriberBuffer)
+	d.mu.Lock()
+	history, done := append([]network.Epoch(nil), d.history...), d.done
+	d.subscribers[ch] = true
+	d.mu.Unlock()
+	for _, e := range history {
+		if data, err := json.Marshal(e); err == nil {
+			writeEvent(w, event{name: "epoch", data: data})
+		}
+	}
+	if done {
+		writeEvent(w, event{name: "done", data: []byte("{}")})
+	}
+	flusher.Flush()
+	defer func() {
+		d.mu.Lock()
+		if d.subscribers[ch] {
+			delete(d.subscribers, ch)
+			close(ch)
+		}
+		d.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeEvent(w, e)
+			flusher.Flush()
+		}
+	}
+}
+
+func (d *Dashboard) historyJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d.History())
+}
+
+func (d *Dashboard) index(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pageTemplate.Execute(w, d)
+}
+
+//--- FUNCTIONS
+
+// New returns an empty dashboard whose page shows the passed title.
+func New(title string) *Dashboard {
+	return &Dashboard{
+		Title:       title,
+		subscribers: make(map[chan event]bool),
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e event) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, e.data)
+}
@@ -0,0 +1,54 @@
+package dashboard_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"neuraldeep/dashboard"
+	"neuraldeep/network"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestDashboard ...
+func TestDashboard(t *testing.T) {
+	d := dashboard.New("Network 2 <test>")
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Assert(t, strings.Contains(string(body), "Network 2 &lt;test&gt;"))
+
+	// An epoch published before the subscription is replayed, the next ones are streamed
+	cost := 0.5
+	d.Publish(network.Epoch{Epoch: 1, TrainingCost: &cost})
+	res, err = http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	assert.Equal(t, res.Header.Get("Content-Type"), "text/event-stream")
+	lines := bufio.NewScanner(res.Body)
+	next := func() string {
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), "data: ") {
+				return lines.Text()
+			}
+		}
+		return ""
+	}
+	assert.Equal(t, next(), `data: {"epoch":1,"trainingCost":0.5}`)
+	d.Publish(network.Epoch{Epoch: 2, TrainingCost: &cost, Duration: 1.5})
+	assert.Equal(t, next(), `data: {"epoch":2,"trainingCost":0.5,"duration":1.5}`)
+	d.Finish()
+	assert.Equal(t, next(), `data: {}`)
+	assert.Equal(t, len(d.History()), 2)
+}
@@ -0,0 +1,221 @@
+package gradcheck
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"neuraldeep/network"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// A package to check the gradients computed by backpropagation against their numerical estimates.
+// For each parameter p, the partial derivative `𝛿C_x / 𝛿p` is approximated by the central difference
+// `(C_x(p + ε) - C_x(p - ε)) / 2ε`, which has an error in O(ε²).
+// Note that any stochastic behaviour of the model (eg. dropout) must be disabled beforehand.
+
+const (
+	BIAS   = "bias"
+	WEIGHT = "weight"
+	GAMMA  = "gamma"
+	BETA   = "beta"
+
+	DEFAULT_EPSILON = 1e-5
+
+	// Differences below this threshold are considered as round-off errors of the central difference
+	ABSOLUTE_TOLERANCE = 1e-9
+
+	// The maximum difference accepted by CompareMatrix(), relative to the estimate when it's above 1
+	MATRIX_TOLERANCE = 1e-6
+)
+
+//--- TYPES
+
+// Model is any network able to compute its gradients through backpropagation.
+type Model interface {
+	Backprop(x *network.Input) (biasesByLayer, weightsByLayer []mat.Matrix)
+	Loss(x *network.Input) float64
+	Parameters() (biases, weights []mat.Matrix)
+}
+
+// BatchModel is any network whose gradients depend on the whole mini-batch, eg. because of batch normalization.
+type BatchModel interface {
+	BackpropBatch(batch network.Dataset) (biasesByLayer, weightsByLayer, gammasByLayer, betasByLayer []mat.Matrix)
+	BatchLoss(batch network.Dataset) float64
+	BatchNormParameters() (gammas, betas []mat.Matrix)
+	Parameters() (biases, weights []mat.Matrix)
+}
+
+// Result holds the comparison of both gradients for a single parameter.
+type Result struct {
+	Kind          string
+	Layer         int
+	Row, Col      int
+	Analytic      float64
+	Numeric       float64
+	RelativeError float64
+}
+
+// Report ...
+type Report struct {
+	Results []Result
+}
+
+//--- METHODS
+
+// Passed tells whether all relative errors are below the passed tolerance.
+func (r Report) Passed(tolerance float64) bool {
+	return r.Worst().RelativeError <= tolerance
+}
+
+// Worst returns the result with the highest relative error.
+func (r Report) Worst() (worst Result) {
+	for _, result := range r.Results {
+		if result.RelativeError > worst.RelativeError {
+			worst = result
+		}
+	}
+	return
+}
+
+// String ...
+func (r Result) String() string {
+	return fmt.Sprintf("%s[%d](%d,%d): analytic=%g numeric=%g relativeError=%g", r.Kind, r.Layer, r.Row, r.Col, r.Analytic, r.Numeric, r.RelativeError)
+}
+
+//--- FUNCTIONS
+
+// Check compares, for every bias and weight of the model, the gradient returned by its Backprop() method for the input 'x'
+// with the central difference estimate computed with the passed step 'epsilon'.
+// The parameters are restored to their original values before returning.
+func Check(model Model, x *network.Input, epsilon float64) (report Report, err error) {
+	if epsilon <= 0 {
+		err = errors.New("epsilon must be positive")
+		return
+	}
+	nablaB, nablaW := model.Backprop(x)
+	biases, weights := model.Parameters()
+	loss := func() float64 {
+		return model.Loss(x)
+	}
+	for l, b := range biases {
+		results, e := compare(loss, epsilon, BIAS, l, b, nablaB[l])
+		if e != nil {
+			err = e
+			return
+		}
+		report.Results = append(report.Results, results...)
+	}
+	for l, w := range weights {
+		results, e := compare(loss, epsilon, WEIGHT, l, w, nablaW[l])
+		if e != nil {
+			err = e
+			return
+		}
+		report.Results = append(report.Results, results...)
+	}
+	return
+}
+
+// CheckBatch does the same as Check() for a model trained on whole mini-batches, including the batch normalization parameters.
+func CheckBatch(model BatchModel, batch network.Dataset, epsilon float64) (report Report, err error) {
+	if epsilon <= 0 {
+		err = errors.New("epsilon must be positive")
+		return
+	}
+	nablaB, nablaW, nablaGamma, nablaBeta := model.BackpropBatch(batch)
+	biases, weights := model.Parameters()
+	gammas, betas := model.BatchNormParameters()
+	loss := func() float64 {
+		return model.BatchLoss(batch)
+	}
+	for _, group := range []struct {
+		kind      string
+		params    []mat.Matrix
+		gradients []mat.Matrix
+	}{
+		{BIAS, biases, nablaB},
+		{WEIGHT, weights, nablaW},
+		{GAMMA, gammas, nablaGamma},
+		{BETA, betas, nablaBeta},
+	} {
+		if len(group.params) != len(group.gradients) {
+			err = fmt.Errorf("%d %s gradients for %d layers", len(group.gradients), group.kind, len(group.params))
+			return
+		}
+		for l, p := range group.params {
+			results, e := compare(loss, epsilon, group.kind, l, p, group.gradients[l])
+			if e != nil {
+				err = e
+				return
+			}
+			report.Results = append(report.Results, results...)
+		}
+	}
+	return
+}
+
+// CompareMatrix checks each value of the gradient 'grad' of the parameter 'p' against its central difference estimate
+// computed with the step DEFAULT_EPSILON, 'loss' returning the cost for the current values of 'p', eg. of a layer or of
+// an operation of a computational graph rather than of a whole network. The values of 'p' are restored before returning.
+// It returns an error describing the first value differing from its estimate by more than `MATRIX_TOLERANCE·max(1, |numeric|)`.
+func CompareMatrix(p *mat.Dense, grad mat.Matrix, loss func() float64) error {
+	results, err := compare(loss, DEFAULT_EPSILON, "matrix", 0, p, grad)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if math.Abs(r.Analytic-r.Numeric) > MATRIX_TOLERANCE*math.Max(1, math.Abs(r.Numeric)) {
+			return fmt.Errorf("(%d,%d): analytic=%g numeric=%g", r.Row, r.Col, r.Analytic, r.Numeric)
+		}
+	}
+	return nil
+}
+
+// RelativeError returns `|a - b| / max(|a|, |b|)`, or 0 if the values are too close to each other to be told apart
+// from numerical noise (see ABSOLUTE_TOLERANCE), eg. when both are almost zero.
+func RelativeError(a, b float64) float64 {
+	if math.Abs(a-b) < ABSOLUTE_TOLERANCE {
+		return 0
+	}
+	return math.Abs(a-b) / math.Max(math.Abs(a), math.Abs(b))
+}
+
+// utility functions
+
+func compare(loss func() float64, epsilon float64, kind string, layer int, param, gradient mat.Matrix) (results []Result, err error) {
+	m, ok := param.(mat.Mutable)
+	if !ok {
+		err = fmt.Errorf("%s layer %d is not mutable", kind, layer)
+		return
+	}
+	r, c := m.Dims()
+	gr, gc := gradient.Dims()
+	if r != gr || c != gc {
+		err = fmt.Errorf("%s layer %d: gradient of size %dx%d for a parameter of size %dx%d", kind, layer, gr, gc, r, c)
+		return
+	}
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			original := m.At(i, j)
+			m.Set(i, j, original+epsilon)
+			plus := loss()
+			m.Set(i, j, original-epsilon)
+			minus := loss()
+			m.Set(i, j, original)
+
+			numeric := (plus - minus) / (2 * epsilon)
+			analytic := gradient.At(i, j)
+			results = append(results, Result{
+				Kind:          kind,
+				Layer:         layer,
+				Row:           i,
+				Col:           j,
+				Analytic:      analytic,
+				Numeric:       numeric,
+				RelativeError: RelativeError(analytic, numeric),
+			})
+		}
+	}
+	return
+}
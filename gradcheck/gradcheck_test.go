@@ -0,0 +1,113 @@
+package gradcheck_test
+
+import (
+	"neuraldeep/cost"
+	"neuraldeep/gradcheck"
+	"neuraldeep/network"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+const tolerance = 1e-6
+
+// TestCheck runs the gradient check on small sigmoid networks for each implementation and cost function.
+func TestCheck(t *testing.T) {
+	input := &network.Input{
+		Data:  []float64{0.3, -0.7, 0.1},
+		Label: network.ToLabel(1, 2),
+	}
+	for _, sizes := range [][]int{{3, 2}, {3, 4, 2}, {3, 5, 4, 2}} {
+		n1, err := network.Init(sizes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := gradcheck.Check(n1, input, gradcheck.DEFAULT_EPSILON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Assert(t, report.Passed(tolerance), "network1 %v sigmoid quadratic: %s", sizes, report.Worst())
+
+		for _, name := range []string{cost.QUADRATIC_COST, cost.CROSS_ENTROPY} {
+			c, _ := cost.New(name)
+			n2, err := network.Initial(sizes, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := gradcheck.Check(n2, input, gradcheck.DEFAULT_EPSILON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, len(report.Results), nbOfParameters(sizes))
+			assert.Assert(t, report.Passed(tolerance), "network2 %v sigmoid %s: %s", sizes, name, report.Worst())
+		}
+	}
+}
+
+// TestCheckBatch runs the gradient check through the batch normalization of the hidden layers.
+func TestCheckBatch(t *testing.T) {
+	batch := network.Dataset{
+		&network.Input{Data: []float64{0.3, -0.7, 0.1}, Label: network.ToLabel(1, 2)},
+		&network.Input{Data: []float64{-0.2, 0.5, 0.9}, Label: network.ToLabel(0, 2)},
+		&network.Input{Data: []float64{0.8, 0.1, -0.4}, Label: network.ToLabel(1, 2)},
+		&network.Input{Data: []float64{-0.6, -0.3, 0.2}, Label: network.ToLabel(0, 2)},
+	}
+	for _, name := range []string{cost.QUADRATIC_COST, cost.CROSS_ENTROPY} {
+		c, _ := cost.New(name)
+		net, err := network.Initial([]int{3, 5, 4, 2}, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		net.SetBatchNorm(true)
+		// Move away from the initial γ = 1 and β = 0
+		gammas, betas := net.BatchNormParameters()
+		for l := range gammas {
+			_, n := gammas[l].Dims()
+			for j := 0; j < n; j++ {
+				gammas[l].(*mat.Dense).Set(0, j, 0.5+0.25*float64(j))
+				betas[l].(*mat.Dense).Set(0, j, 0.1*float64(j)-0.2)
+			}
+		}
+		report, err := gradcheck.CheckBatch(net, batch, gradcheck.DEFAULT_EPSILON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, len(report.Results), nbOfParameters([]int{3, 5, 4, 2})+2*(5+4))
+		assert.Assert(t, report.Passed(tolerance), "network2 batch normalization sigmoid %s: %s", name, report.Worst())
+	}
+}
+
+// TestCompareMatrix ...
+func TestCompareMatrix(t *testing.T) {
+	p := mat.NewDense(2, 3, []float64{0.5, -1, 2, 0, 3, -0.25})
+	original := mat.DenseCopyOf(p)
+	loss := func() float64 {
+		var squares mat.Dense
+		squares.MulElem(p, p)
+		return mat.Sum(&squares)
+	}
+	var grad mat.Dense
+	grad.Scale(2, p)
+	assert.NilError(t, gradcheck.CompareMatrix(p, &grad, loss))
+	assert.Assert(t, mat.Equal(p, original))
+
+	grad.Set(1, 1, 5)
+	assert.ErrorContains(t, gradcheck.CompareMatrix(p, &grad, loss), "(1,1): analytic=5 numeric=6")
+	assert.ErrorContains(t, gradcheck.CompareMatrix(p, mat.NewDense(3, 2, nil), loss), "gradient of size 3x2 for a parameter of size 2x3")
+}
+
+// TestRelativeError ...
+func TestRelativeError(t *testing.T) {
+	assert.Equal(t, gradcheck.RelativeError(1, 1), 0.)
+	assert.Equal(t, gradcheck.RelativeError(2, 1), 0.5)
+	assert.Equal(t, gradcheck.RelativeError(-1, 1), 2.)
+	assert.Equal(t, gradcheck.RelativeError(0, 1e-15), 0.)
+}
+
+func nbOfParameters(sizes []int) (n int) {
+	for i := 1; i < len(sizes); i++ {
+		n += sizes[i] + sizes[i]*sizes[i-1]
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package layer
+
+import (
+	"errors"
+	"neuraldeep/activation"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	RELU    = "relu"
+	SIGMOID = "sigmoid"
+	TANH    = "tanh"
+)
+
+//--- TYPES
+
+// Activation applies an activation function to each of its inputs.
+type Activation struct {
+	Name  string
+	fn    func(i, j int, z float64) float64
+	prime func(i, j int, z float64) float64
+	z     mat.Matrix
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *Activation) Backward(grad mat.Matrix) mat.Matrix {
+	return matrix.Multiply(grad, matrix.Apply(l.prime, l.z))
+}
+
+// Forward ...
+func (l *Activation) Forward(z mat.Matrix, training bool) mat.Matrix {
+	l.z = z
+	return matrix.Apply(l.fn, z)
+}
+
+// GetName ...
+func (l *Activation) GetName() string {
+	return l.Name
+}
+
+// Grads ...
+func (l *Activation) Grads() []*mat.Dense {
+	return nil
+}
+
+// Params ...
+func (l *Activation) Params() []*mat.Dense {
+	return nil
+}
+
+//--- FUNCTIONS
+
+// NewActivation ...
+func NewActivation(name string) (*Activation, error) {
+	switch name {
+	case SIGMOID:
+		return &Activation{Name: name, fn: activation.Sigmoid, prime: activation.SigmoidPrime}, nil
+	case TANH:
+		return &Activation{Name: name, fn: activation.Tanh, prime: activation.TanhPrime}, nil
+	case RELU:
+		return &Activation{Name: name, fn: activation.ReLU, prime: activation.ReLUPrime}, nil
+	default:
+		return nil, errors.New("unavailable activation function")
+	}
+}
@@ -0,0 +1,130 @@
+package layer
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	BATCH_NORM = "batchNorm"
+
+	DEFAULT_MOMENTUM = 0.9
+	DEFAULT_EPSILON  = 1e-5
+)
+
+//--- TYPES
+
+// BatchNorm standardizes each of its inputs over the mini-batch then scales and shifts them, ie. `y = γ x̂ + β`
+// (Ioffe & Szegedy, 2015). Running averages of the statistics of the mini-batches are kept for inference.
+type BatchNorm struct {
+	Gamma       *mat.Dense // 1×n
+	Beta        *mat.Dense // 1×n
+	RunningMean []float64
+	RunningVar  []float64
+	Momentum    float64
+	Epsilon     float64
+	nablaGamma  *mat.Dense
+	nablaBeta   *mat.Dense
+	xHat        *mat.Dense
+	invStd      []float64
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *BatchNorm) Backward(grad mat.Matrix) mat.Matrix {
+	m, n := grad.Dims()
+	dx := mat.NewDense(m, n, nil)
+	for j := 0; j < n; j++ {
+		var sumGrad, sumGradXHat float64
+		for i := 0; i < m; i++ {
+			sumGrad += grad.At(i, j)
+			sumGradXHat += grad.At(i, j) * l.xHat.At(i, j)
+		}
+		l.nablaGamma.Set(0, j, sumGradXHat)
+		l.nablaBeta.Set(0, j, sumGrad)
+		// ∂C/∂x = γ/(m·σ) (m·∂C/∂y - ∑∂C/∂y - x̂ ∑(∂C/∂y x̂))
+		k := l.Gamma.At(0, j) * l.invStd[j] / float64(m)
+		for i := 0; i < m; i++ {
+			dx.Set(i, j, k*(float64(m)*grad.At(i, j)-sumGrad-l.xHat.At(i, j)*sumGradXHat))
+		}
+	}
+	return dx
+}
+
+// Forward normalizes the inputs with the statistics of the mini-batch when training, updating the running averages,
+// and with the running averages otherwise.
+func (l *BatchNorm) Forward(x mat.Matrix, training bool) mat.Matrix {
+	m, n := x.Dims()
+	mean, variance := make([]float64, n), make([]float64, n)
+	if training {
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				mean[j] += x.At(i, j) / float64(m)
+			}
+			for i := 0; i < m; i++ {
+				variance[j] += math.Pow(x.At(i, j)-mean[j], 2) / float64(m)
+			}
+			unbiased := variance[j]
+			if m > 1 {
+				unbiased *= float64(m) / float64(m-1)
+			}
+			l.RunningMean[j] = l.Momentum*l.RunningMean[j] + (1-l.Momentum)*mean[j]
+			l.RunningVar[j] = l.Momentum*l.RunningVar[j] + (1-l.Momentum)*unbiased
+		}
+	} else {
+		copy(mean, l.RunningMean)
+		copy(variance, l.RunningVar)
+	}
+	l.invStd = make([]float64, n)
+	for j := range l.invStd {
+		l.invStd[j] = 1 / math.Sqrt(variance[j]+l.Epsilon)
+	}
+	l.xHat = mat.NewDense(m, n, nil)
+	l.xHat.Apply(func(i, j int, _ float64) float64 {
+		return (x.At(i, j) - mean[j]) * l.invStd[j]
+	}, l.xHat)
+	var y mat.Dense
+	y.Apply(func(_, j int, v float64) float64 {
+		return l.Gamma.At(0, j)*v + l.Beta.At(0, j)
+	}, l.xHat)
+	return &y
+}
+
+// GetName ...
+func (l *BatchNorm) GetName() string {
+	return BATCH_NORM
+}
+
+// Grads returns the gradients of `γ` and `β`.
+func (l *BatchNorm) Grads() []*mat.Dense {
+	return []*mat.Dense{l.nablaGamma, l.nablaBeta}
+}
+
+// Params returns `γ` and `β`.
+func (l *BatchNorm) Params() []*mat.Dense {
+	return []*mat.Dense{l.Gamma, l.Beta}
+}
+
+//--- FUNCTIONS
+
+// NewBatchNorm returns the batch normalization of 'n' inputs, with `γ = 1`, `β = 0` and running statistics
+// of a standard distribution.
+func NewBatchNorm(n int) *BatchNorm {
+	l := &BatchNorm{
+		Gamma:       mat.NewDense(1, n, nil),
+		Beta:        mat.NewDense(1, n, nil),
+		RunningMean: make([]float64, n),
+		RunningVar:  make([]float64, n),
+		Momentum:    DEFAULT_MOMENTUM,
+		Epsilon:     DEFAULT_EPSILON,
+		nablaGamma:  mat.NewDense(1, n, nil),
+		nablaBeta:   mat.NewDense(1, n, nil),
+	}
+	for j := 0; j < n; j++ {
+		l.Gamma.Set(0, j, 1)
+		l.RunningVar[j] = 1
+	}
+	return l
+}
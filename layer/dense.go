@@ -0,0 +1,73 @@
+package layer
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const DENSE = "dense"
+
+//--- TYPES
+
+// Dense is a fully connected layer computing the weighted inputs `z = x·Wᵀ + b`.
+type Dense struct {
+	Weights *mat.Dense // out×in
+	Biases  *mat.Dense // 1×out
+	nablaW  *mat.Dense
+	nablaB  *mat.Dense
+	x       mat.Matrix
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *Dense) Backward(grad mat.Matrix) mat.Matrix {
+	l.nablaW.Mul(grad.T(), l.x)
+	l.nablaB.Copy(matrix.ColumnSums(grad))
+	var dx mat.Dense
+	dx.Mul(grad, l.Weights)
+	return &dx
+}
+
+// Forward ...
+func (l *Dense) Forward(x mat.Matrix, training bool) mat.Matrix {
+	l.x = x
+	var z mat.Dense
+	z.Mul(x, l.Weights.T())
+	z.Apply(func(_, j int, v float64) float64 {
+		return v + l.Biases.At(0, j)
+	}, &z)
+	return &z
+}
+
+// GetName ...
+func (l *Dense) GetName() string {
+	return DENSE
+}
+
+// Grads returns the gradients of the weights and the biases.
+func (l *Dense) Grads() []*mat.Dense {
+	return []*mat.Dense{l.nablaW, l.nablaB}
+}
+
+// Params returns the weights and the biases.
+func (l *Dense) Params() []*mat.Dense {
+	return []*mat.Dense{l.Weights, l.Biases}
+}
+
+//--- FUNCTIONS
+
+// NewDense returns a layer of 'out' neurons fed by 'in' inputs, its weights being drawn from a Gaussian distribution
+// of mean 0 and standard deviation `1/√in` and its biases from a standard one, as by the default initializer of Network2.
+// If a random generator is passed, they are drawn from it instead of the global one.
+func NewDense(in, out int, rng ...*rand.Rand) *Dense {
+	return &Dense{
+		Weights: mat.DenseCopyOf(matrix.Gaussian(out, in, 0, 1/math.Sqrt(float64(in)), rng...)),
+		Biases:  mat.DenseCopyOf(matrix.Gaussian(1, out, 0, 1, rng...)),
+		nablaW:  mat.NewDense(out, in, nil),
+		nablaB:  mat.NewDense(1, out, nil),
+	}
+}
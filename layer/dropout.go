@@ -0,0 +1,82 @@
+package layer
+
+import (
+	"errors"
+	"math/rand"
+	"neuraldeep/utils/matrix"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const DROPOUT = "dropout"
+
+//--- TYPES
+
+// Dropout randomly drops each of its inputs with the probability 'Rate' during training, scaling the kept ones by
+// `1 / (1 - Rate)` (ie. inverted dropout) so that nothing has to be done at inference time.
+type Dropout struct {
+	Rate float64
+	rng  *rand.Rand
+	mask mat.Matrix
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *Dropout) Backward(grad mat.Matrix) mat.Matrix {
+	if l.mask == nil {
+		return grad
+	}
+	return matrix.Multiply(grad, l.mask)
+}
+
+// Forward ...
+func (l *Dropout) Forward(x mat.Matrix, training bool) mat.Matrix {
+	if !training || l.Rate == 0 {
+		l.mask = nil
+		return x
+	}
+	r, c := x.Dims()
+	mask := mat.NewDense(r, c, nil)
+	mask.Apply(func(_, _ int, _ float64) float64 {
+		if l.rng.Float64() < l.Rate {
+			return 0
+		}
+		return 1 / (1 - l.Rate)
+	}, mask)
+	l.mask = mask
+	return matrix.Multiply(x, mask)
+}
+
+// GetName ...
+func (l *Dropout) GetName() string {
+	return DROPOUT
+}
+
+// Grads ...
+func (l *Dropout) Grads() []*mat.Dense {
+	return nil
+}
+
+// Params ...
+func (l *Dropout) Params() []*mat.Dense {
+	return nil
+}
+
+//--- FUNCTIONS
+
+// NewDropout returns a dropout layer of the passed rate, in `[0, 1)`.
+// If a random generator is passed, it is used to draw the masks instead of a time-seeded one.
+func NewDropout(rate float64, rng ...*rand.Rand) (*Dropout, error) {
+	if rate < 0 || rate >= 1 {
+		return nil, errors.New("dropout rate must be in [0, 1)")
+	}
+	l := &Dropout{Rate: rate}
+	if len(rng) > 0 && rng[0] != nil {
+		l.rng = rng[0]
+	} else {
+		l.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return l, nil
+}
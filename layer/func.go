@@ -0,0 +1,84 @@
+package layer
+
+import (
+	"neuraldeep/autodiff"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+//--- TYPES
+
+// Func is a layer written as forward code only, its gradients being computed by automatic differentiation.
+// 'fn' computes the outputs of the rows of 'x' from the parameters, in the order they were passed to NewFunc().
+type Func struct {
+	Name   string
+	fn     func(x *autodiff.Node, params []*autodiff.Node) *autodiff.Node
+	params []*mat.Dense
+	grads  []*mat.Dense
+	tape   *autodiff.Tape
+	x      *autodiff.Node
+	vars   []*autodiff.Node
+	out    *autodiff.Node
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *Func) Backward(grad mat.Matrix) mat.Matrix {
+	l.tape.Backward(l.out, grad)
+	for i, v := range l.vars {
+		if v.Grad == nil {
+			l.grads[i].Zero()
+		} else {
+			l.grads[i].Copy(v.Grad)
+		}
+	}
+	if l.x.Grad == nil {
+		r, c := l.x.Value.Dims()
+		return mat.NewDense(r, c, nil)
+	}
+	return l.x.Grad
+}
+
+// Forward ...
+func (l *Func) Forward(x mat.Matrix, training bool) mat.Matrix {
+	l.tape = autodiff.NewTape()
+	l.x = l.tape.Variable(x)
+	l.vars = make([]*autodiff.Node, len(l.params))
+	for i, p := range l.params {
+		l.vars[i] = l.tape.Variable(p)
+	}
+	l.out = l.fn(l.x, l.vars)
+	return l.out.Value
+}
+
+// GetName ...
+func (l *Func) GetName() string {
+	return l.Name
+}
+
+// Grads ...
+func (l *Func) Grads() []*mat.Dense {
+	return l.grads
+}
+
+// Params ...
+func (l *Func) Params() []*mat.Dense {
+	return l.params
+}
+
+//--- FUNCTIONS
+
+// NewFunc returns a layer computing 'fn' with the passed learnable parameters, eg. a dense layer with a tanh activation:
+//
+//	layer.NewFunc("denseTanh", func(x *autodiff.Node, p []*autodiff.Node) *autodiff.Node {
+//		return x.Dot(p[0].T()).AddRow(p[1]).Tanh()
+//	}, weights, biases)
+func NewFunc(name string, fn func(x *autodiff.Node, params []*autodiff.Node) *autodiff.Node, params ...*mat.Dense) *Func {
+	l := &Func{Name: name, fn: fn, params: params}
+	for _, p := range params {
+		r, c := p.Dims()
+		l.grads = append(l.grads, mat.NewDense(r, c, nil))
+	}
+	return l
+}
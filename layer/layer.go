@@ -0,0 +1,24 @@
+package layer
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// A package of composable layers, each one being a single step of the computation of a network, eg. a weighted sum
+// or an activation function, so that a new kind of layer doesn't need a new network type: a Sequential model stacks them.
+// The inputs of a mini-batch are fed at once as the rows of a matrix, as in the batched paths of the network package.
+
+//--- TYPES
+
+// Layer is a differentiable function of a mini-batch, possibly with learnable parameters.
+// Forward() computes the outputs of the rows of 'x', keeping what Backward() needs, and 'training' tells whether it's
+// a training pass, eg. to apply dropout. Backward() takes the gradients of the cost with respect to the outputs of the last
+// forward pass, stores those of the parameters summed over the mini-batch, and returns the gradients with respect to the inputs.
+// Params() and Grads() return the parameters and their gradients in the same order, as matrices sharing the layer's data.
+type Layer interface {
+	Forward(x mat.Matrix, training bool) mat.Matrix
+	Backward(grad mat.Matrix) mat.Matrix
+	Params() []*mat.Dense
+	Grads() []*mat.Dense
+	GetName() string
+}
@@ -0,0 +1,140 @@
+package layer_test
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/autodiff"
+	"neuraldeep/cost"
+	"neuraldeep/gradcheck"
+	"neuraldeep/layer"
+	"neuraldeep/network"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestBackward compares the gradients of each kind of layer with their central difference estimates.
+func TestBackward(t *testing.T) {
+	data := network.Synthetic(6, 5, 3, rand.New(rand.NewSource(1)))
+	x, y := layer.Batch(data)
+	for _, stack := range []struct {
+		loss   string
+		layers func() []layer.Layer
+	}{
+		{cost.CROSS_ENTROPY, func() []layer.Layer {
+			return []layer.Layer{layer.NewDense(5, 4), activation(t, layer.SIGMOID), layer.NewDense(4, 3), activation(t, layer.SIGMOID)}
+		}},
+		{cost.QUADRATIC_COST, func() []layer.Layer {
+			return []layer.Layer{layer.NewDense(5, 4), layer.NewBatchNorm(4), activation(t, layer.TANH), layer.NewDense(4, 3)}
+		}},
+		{cost.QUADRATIC_COST, func() []layer.Layer {
+			dense := layer.NewDense(5, 4)
+			tanh := layer.NewFunc("denseTanh", func(x *autodiff.Node, p []*autodiff.Node) *autodiff.Node {
+				return x.Dot(p[0].T()).AddRow(p[1]).Tanh()
+			}, dense.Weights, dense.Biases)
+			return []layer.Layer{tanh, layer.NewDense(4, 3)}
+		}},
+		{layer.LOG_LIKELIHOOD, func() []layer.Layer {
+			return []layer.Layer{layer.NewDense(5, 4), activation(t, layer.RELU), layer.NewDense(4, 3), &layer.Softmax{}}
+		}},
+	} {
+		loss, err := layer.NewLoss(stack.loss)
+		assert.NilError(t, err)
+		model := layer.NewSequential(loss, stack.layers()...)
+		lossOf := func(x mat.Matrix) float64 {
+			return loss.Function(model.Forward(x, true), y)
+		}
+		dx := model.BackwardLoss(model.Forward(x, true), y)
+		grads := model.Grads()
+		for i, p := range model.Params() {
+			assert.NilError(t, gradcheck.CompareMatrix(p, grads[i], func() float64 { return lossOf(x) }), model.String())
+		}
+		assert.NilError(t, gradcheck.CompareMatrix(x, dx, func() float64 { return lossOf(x) }), model.String())
+	}
+}
+
+// TestDropout ...
+func TestDropout(t *testing.T) {
+	l, err := layer.NewDropout(0.5, rand.New(rand.NewSource(1)))
+	assert.NilError(t, err)
+	x := mat.NewDense(20, 10, nil)
+	x.Apply(func(_, _ int, _ float64) float64 { return 1 }, x)
+	assert.Assert(t, mat.Equal(l.Forward(x, false), x))
+
+	out := l.Forward(x, true)
+	grad := l.Backward(x)
+	dropped := 0
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 10; j++ {
+			v := out.At(i, j)
+			assert.Assert(t, v == 0 || v == 2)
+			assert.Equal(t, grad.At(i, j), v)
+			if v == 0 {
+				dropped++
+			}
+		}
+	}
+	assert.Assert(t, dropped > 70 && dropped < 130)
+
+	_, err = layer.NewDropout(1)
+	assert.Error(t, err, "dropout rate must be in [0, 1)")
+}
+
+// TestSaturatedOutput checks that the gradient of the loss doesn't vanish when the outputs saturate on the wrong side.
+func TestSaturatedOutput(t *testing.T) {
+	for _, tt := range []struct {
+		loss     layer.Loss
+		last     layer.Layer
+		z, y     []float64
+		expected []float64
+	}{
+		{layer.CrossEntropyLoss{}, activation(t, layer.SIGMOID), []float64{40, -40}, []float64{0, 1}, []float64{1, -1}},
+		{layer.LogLikelihoodLoss{}, &layer.Softmax{}, []float64{50, 0, 0}, []float64{0, 1, 0}, []float64{1, -1, 0}},
+	} {
+		model := layer.NewSequential(tt.loss, tt.last)
+		z, y := mat.NewDense(1, len(tt.z), tt.z), mat.NewDense(1, len(tt.y), tt.y)
+		dz := model.BackwardLoss(model.Forward(z, true), y)
+		for j, e := range tt.expected {
+			assert.Assert(t, math.Abs(dz.At(0, j)-e) < 1e-9, "%s (%d): %g != %g", model, j, dz.At(0, j), e)
+		}
+
+		// Chaining the gradient of the clipped loss with the derivative of the layer makes it collapse
+		collapsed := model.Backward(tt.loss.Gradient(model.Forward(z, true), y))
+		assert.Assert(t, math.Abs(collapsed.At(0, 1)) < 1e-3)
+	}
+}
+
+// TestSequential ...
+func TestSequential(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// The class of each input is the index of its largest value
+	data := network.Synthetic(300, 4, 4, rng)
+	for _, input := range data {
+		input.Label = network.ToLabel(float64(network.Argmax(mat.NewDense(1, 4, input.Data))), 4)
+	}
+	dropout, err := layer.NewDropout(0.1, rng)
+	assert.NilError(t, err)
+	model := layer.NewSequential(layer.LogLikelihoodLoss{},
+		layer.NewDense(4, 20), layer.NewBatchNorm(20), activation(t, layer.SIGMOID), dropout, layer.NewDense(20, 4), &layer.Softmax{})
+	assert.Equal(t, model.String(), "dense → batchNorm → sigmoid → dropout → dense → softmax")
+	assert.Equal(t, len(model.Params()), 6)
+
+	before := model.TotalCost(data)
+	for epoch := 0; epoch < 30; epoch++ {
+		for _, miniBatch := range data.MiniBatches(10) {
+			model.UpdateMiniBatch(miniBatch, 0.5)
+		}
+	}
+	assert.Assert(t, model.TotalCost(data) < before/2)
+	assert.Assert(t, model.Evaluate(data) > 240)
+
+	output := model.FeedForward(data[0].ToVector())
+	assert.Assert(t, math.Abs(mat.Sum(output)-1) < 1e-9)
+}
+
+func activation(t *testing.T, name string) layer.Layer {
+	l, err := layer.NewActivation(name)
+	assert.NilError(t, err)
+	return l
+}
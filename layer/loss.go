@@ -0,0 +1,137 @@
+package layer
+
+import (
+	"errors"
+	"math"
+	"neuraldeep/cost"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	LOG_LIKELIHOOD = "logLikelihood"
+
+	// The outputs are kept this far from 0 and 1 when computing logarithms and their derivatives
+	CLIP = 1e-12
+)
+
+//--- TYPES
+
+// Loss is the cost of a mini-batch whose outputs 'a' and desired outputs 'y' are the rows of the passed matrices.
+// Unlike cost.Cost, its gradient is taken with respect to the outputs, the last layer of a model being any layer.
+type Loss interface {
+	Function(a, y mat.Matrix) float64
+	Gradient(a, y mat.Matrix) mat.Matrix
+	GetName() string
+}
+
+// CrossEntropyLoss is the cross-entropy of independent binary outputs, eg. of a sigmoid layer.
+type CrossEntropyLoss struct{}
+
+// LogLikelihoodLoss is the negative log-likelihood of the desired class, eg. for a softmax layer.
+type LogLikelihoodLoss struct{}
+
+// QuadraticLoss ...
+type QuadraticLoss struct{}
+
+//--- METHODS
+
+// Function returns `-∑ y ln(a) + (1 - y) ln(1 - a)`.
+func (CrossEntropyLoss) Function(a, y mat.Matrix) (c float64) {
+	each(a, y, func(a, y float64) {
+		a = clip(a)
+		c -= y*math.Log(a) + (1-y)*math.Log(1-a)
+	})
+	return
+}
+
+// Gradient returns `(a - y) / a(1 - a)`.
+func (CrossEntropyLoss) Gradient(a, y mat.Matrix) mat.Matrix {
+	return gradient(a, y, func(a, y float64) float64 {
+		a = clip(a)
+		return (a - y) / (a * (1 - a))
+	})
+}
+
+// GetName ...
+func (CrossEntropyLoss) GetName() string {
+	return cost.CROSS_ENTROPY
+}
+
+// Function returns `-∑ y ln(a)`.
+func (LogLikelihoodLoss) Function(a, y mat.Matrix) (c float64) {
+	each(a, y, func(a, y float64) {
+		c -= y * math.Log(clip(a))
+	})
+	return
+}
+
+// Gradient returns `-y / a`.
+func (LogLikelihoodLoss) Gradient(a, y mat.Matrix) mat.Matrix {
+	return gradient(a, y, func(a, y float64) float64 {
+		return -y / clip(a)
+	})
+}
+
+// GetName ...
+func (LogLikelihoodLoss) GetName() string {
+	return LOG_LIKELIHOOD
+}
+
+// Function returns `½ ∑ (a - y)²`.
+func (QuadraticLoss) Function(a, y mat.Matrix) (c float64) {
+	each(a, y, func(a, y float64) {
+		c += 0.5 * (a - y) * (a - y)
+	})
+	return
+}
+
+// Gradient returns `a - y`.
+func (QuadraticLoss) Gradient(a, y mat.Matrix) mat.Matrix {
+	return gradient(a, y, func(a, y float64) float64 {
+		return a - y
+	})
+}
+
+// GetName ...
+func (QuadraticLoss) GetName() string {
+	return cost.QUADRATIC_COST
+}
+
+//--- FUNCTIONS
+
+// NewLoss ...
+func NewLoss(name string) (Loss, error) {
+	switch name {
+	case cost.CROSS_ENTROPY:
+		return CrossEntropyLoss{}, nil
+	case LOG_LIKELIHOOD:
+		return LogLikelihoodLoss{}, nil
+	case cost.QUADRATIC_COST:
+		return QuadraticLoss{}, nil
+	default:
+		return nil, errors.New("unavailable loss function")
+	}
+}
+
+func clip(a float64) float64 {
+	return math.Min(math.Max(a, CLIP), 1-CLIP)
+}
+
+func each(a, y mat.Matrix, fn func(a, y float64)) {
+	r, c := a.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			fn(a.At(i, j), y.At(i, j))
+		}
+	}
+}
+
+func gradient(a, y mat.Matrix, fn func(a, y float64) float64) mat.Matrix {
+	r, c := a.Dims()
+	g := mat.NewDense(r, c, nil)
+	g.Apply(func(i, j int, _ float64) float64 {
+		return fn(a.At(i, j), y.At(i, j))
+	}, g)
+	return g
+}
@@ -0,0 +1,175 @@
+package layer
+
+import (
+	"fmt"
+	"math"
+	"neuraldeep/network"
+	"neuraldeep/utils/matrix"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const SEQUENTIAL = "sequential"
+
+//--- TYPES
+
+// Sequential is a model feeding the outputs of each of its layers to the next one, trained by stochastic gradient descent
+// against its loss. Being a Layer itself, it can be nested in another model.
+type Sequential struct {
+	Layers []Layer
+	Loss   Loss
+}
+
+//--- METHODS
+
+// Backward propagates the gradients of the cost with respect to the outputs through the layers, from the last one.
+func (s *Sequential) Backward(grad mat.Matrix) mat.Matrix {
+	return s.backward(grad, len(s.Layers)-1)
+}
+
+// BackwardLoss propagates the gradient of the model's loss for the outputs 'a' and the desired outputs 'y' through the layers.
+// When the last layer is a sigmoid trained against the cross-entropy loss, or a softmax against the log-likelihood one
+// with one-hot desired outputs, the gradient with respect to its inputs is `a - y`: it is fed directly to the layer before,
+// since chaining the gradient of the loss, whose outputs are clipped, with the derivative of the last layer would make
+// it vanish as the outputs saturate, right when they are the most wrong.
+func (s *Sequential) BackwardLoss(a, y mat.Matrix) mat.Matrix {
+	last := len(s.Layers) - 1
+	if last >= 0 && s.fused(s.Layers[last]) {
+		return s.backward(matrix.Subtract(a, y), last-1)
+	}
+	return s.Backward(s.Loss.Gradient(a, y))
+}
+
+// Evaluate returns the number of test inputs for which the model outputs the correct result,
+// ie. the index of its highest activation.
+func (s *Sequential) Evaluate(test network.Dataset) (sum int) {
+	for _, input := range test {
+		if network.Argmax(s.FeedForward(input.ToVector())) == int(math.Round(input.Label.Value)) {
+			sum++
+		}
+	}
+	return
+}
+
+// FeedForward returns the 1×n output of the model for the input 'a', in inference mode.
+func (s *Sequential) FeedForward(a mat.Vector) mat.Matrix {
+	return s.Forward(a.T(), false)
+}
+
+// Forward ...
+func (s *Sequential) Forward(x mat.Matrix, training bool) mat.Matrix {
+	for _, l := range s.Layers {
+		x = l.Forward(x, training)
+	}
+	return x
+}
+
+// GetName ...
+func (s *Sequential) GetName() string {
+	return SEQUENTIAL
+}
+
+// Grads returns the gradients of the parameters of all the layers, in the order of Params().
+func (s *Sequential) Grads() (grads []*mat.Dense) {
+	for _, l := range s.Layers {
+		grads = append(grads, l.Grads()...)
+	}
+	return
+}
+
+// Params returns the parameters of all the layers, layer by layer.
+func (s *Sequential) Params() (params []*mat.Dense) {
+	for _, l := range s.Layers {
+		params = append(params, l.Params()...)
+	}
+	return
+}
+
+// SGD trains the model using mini-batch stochastic gradient descent, like Network1.SGD().
+// If a 'test' dataset is provided, the model is evaluated against it after each epoch.
+func (s *Sequential) SGD(training network.Dataset, epochs, miniBatchSize int, eta float64, test ...network.Dataset) {
+	for j := 0; j < epochs; j++ {
+		training.Shuffle()
+		for _, miniBatch := range training.MiniBatches(miniBatchSize) {
+			s.UpdateMiniBatch(miniBatch, eta)
+		}
+		if len(test) > 0 {
+			fmt.Printf("epoch %d: %d / %d\n", j+1, s.Evaluate(test[0]), len(test[0]))
+		} else {
+			fmt.Printf("epoch %d complete\n", j+1)
+		}
+	}
+}
+
+// String returns the names of the layers, eg. `dense → sigmoid → dense → softmax`.
+func (s *Sequential) String() string {
+	names := make([]string, len(s.Layers))
+	for i, l := range s.Layers {
+		names[i] = l.GetName()
+	}
+	return strings.Join(names, " → ")
+}
+
+// TotalCost returns the mean loss of the model over the dataset, in inference mode.
+func (s *Sequential) TotalCost(data network.Dataset) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	x, y := Batch(data)
+	return s.Loss.Function(s.Forward(x, false), y) / float64(len(data))
+}
+
+// UpdateMiniBatch moves the parameters of the model against the gradient of its loss over the mini-batch, at the learning rate 'eta'.
+func (s *Sequential) UpdateMiniBatch(miniBatch network.Dataset, eta float64) {
+	x, y := Batch(miniBatch)
+	s.BackwardLoss(s.Forward(x, true), y)
+	grads := s.Grads()
+	for i, p := range s.Params() {
+		matrix.AddScaledTo(p, p, -eta/float64(len(miniBatch)), grads[i])
+	}
+}
+
+// backward propagates the gradients 'grad' through the layers, from the one of index 'from'.
+func (s *Sequential) backward(grad mat.Matrix, from int) mat.Matrix {
+	for i := from; i >= 0; i-- {
+		grad = s.Layers[i].Backward(grad)
+	}
+	return grad
+}
+
+// fused tells whether the gradient of the loss and the derivative of the last layer 'l' simplify to `a - y`.
+func (s *Sequential) fused(l Layer) bool {
+	switch l := l.(type) {
+	case *Activation:
+		_, ok := s.Loss.(CrossEntropyLoss)
+		return ok && l.Name == SIGMOID
+	case *Softmax:
+		_, ok := s.Loss.(LogLikelihoodLoss)
+		return ok
+	}
+	return false
+}
+
+//--- FUNCTIONS
+
+// Batch returns the inputs of the dataset and their label vectors as the rows of two matrices.
+func Batch(data network.Dataset) (x, y *mat.Dense) {
+	x = mat.NewDense(len(data), len(data[0].Data), nil)
+	y = mat.NewDense(len(data), data[0].Label.Vector.Len(), nil)
+	for i, input := range data {
+		x.SetRow(i, input.Data)
+		for j := 0; j < input.Label.Vector.Len(); j++ {
+			y.Set(i, j, input.Label.Vector.AtVec(j))
+		}
+	}
+	return
+}
+
+// NewSequential returns a model stacking the passed layers, to be trained against the 'loss'.
+func NewSequential(loss Loss, layers ...Layer) *Sequential {
+	return &Sequential{
+		Layers: layers,
+		Loss:   loss,
+	}
+}
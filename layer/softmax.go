@@ -0,0 +1,74 @@
+package layer
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const SOFTMAX = "softmax"
+
+//--- TYPES
+
+// Softmax turns each row of its inputs into a probability distribution, ie. `aⱼ = exp(zⱼ) / ∑ₖ exp(zₖ)`.
+type Softmax struct {
+	a *mat.Dense
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *Softmax) Backward(grad mat.Matrix) mat.Matrix {
+	m, n := grad.Dims()
+	dz := mat.NewDense(m, n, nil)
+	for i := 0; i < m; i++ {
+		dot := 0.
+		for j := 0; j < n; j++ {
+			dot += grad.At(i, j) * l.a.At(i, j)
+		}
+		// ∂C/∂zⱼ = aⱼ (∂C/∂aⱼ - ∑ₖ ∂C/∂aₖ aₖ)
+		for j := 0; j < n; j++ {
+			dz.Set(i, j, l.a.At(i, j)*(grad.At(i, j)-dot))
+		}
+	}
+	return dz
+}
+
+// Forward ...
+func (l *Softmax) Forward(z mat.Matrix, training bool) mat.Matrix {
+	m, n := z.Dims()
+	a := mat.NewDense(m, n, nil)
+	for i := 0; i < m; i++ {
+		// Shifting by the maximum doesn't change the result but avoids overflows
+		max := math.Inf(-1)
+		for j := 0; j < n; j++ {
+			max = math.Max(max, z.At(i, j))
+		}
+		sum := 0.
+		for j := 0; j < n; j++ {
+			e := math.Exp(z.At(i, j) - max)
+			a.Set(i, j, e)
+			sum += e
+		}
+		for j := 0; j < n; j++ {
+			a.Set(i, j, a.At(i, j)/sum)
+		}
+	}
+	l.a = a
+	return a
+}
+
+// GetName ...
+func (l *Softmax) GetName() string {
+	return SOFTMAX
+}
+
+// Grads ...
+func (l *Softmax) Grads() []*mat.Dense {
+	return nil
+}
+
+// Params ...
+func (l *Softmax) Params() []*mat.Dense {
+	return nil
+}
@@ -1,14 +1,40 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"math/rand"
+	"net/http"
+	"neuraldeep/augment"
 	"neuraldeep/cost"
+	"neuraldeep/dashboard"
+	"neuraldeep/layer"
+	"neuraldeep/metrics"
 	"neuraldeep/network"
+	"neuraldeep/preprocess"
+	"neuraldeep/recurrent"
+	"neuraldeep/regularization"
+	"neuraldeep/server"
+	"neuraldeep/tuning"
+	"neuraldeep/visual"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	BENCH_DURATION = 3 * time.Second
+	BENCH_SAMPLES  = 1000
+
+	SAMPLE_TEMPERATURE = 0.8
 )
 
 // Usage:
@@ -22,14 +48,59 @@ import (
 // `& ./neuraldeep -n=1 -op=predict -layers="784,300,10" -label=5 -data="0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,3,18,18,18,126,136,175,26,166,255,247,127,0,0,0,0,0,0,0,0,0,0,0,0,30,36,94,154,170,253,253,253,253,253,225,172,253,242,195,64,0,0,0,0,0,0,0,0,0,0,0,49,238,253,253,253,253,253,253,253,253,251,93,82,82,56,39,0,0,0,0,0,0,0,0,0,0,0,0,18,219,253,253,253,253,253,198,182,247,241,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,80,156,107,253,253,205,11,0,43,154,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,14,1,154,253,90,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,139,253,190,2,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,11,190,253,70,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,35,241,225,160,108,1,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,81,240,253,253,119,25,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,45,186,253,253,150,27,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,16,93,252,253,187,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,249,253,249,64,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,46,130,183,253,253,207,2,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,39,148,229,253,253,253,250,182,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,24,114,221,253,253,253,253,201,78,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,23,66,213,253,253,253,253,198,81,2,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,18,171,219,253,253,253,253,195,80,9,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,55,172,226,253,253,253,253,244,133,11,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,136,253,253,253,212,135,132,16,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0" -load=true`
 //
 // `$ ./neuraldeep -n=2 -op=train -cost=crossEntropy -layers="784,300,10" -data=training -useMNIST=true -epochs=30 -size=10 -eta=0.12 -lambda=5.0 -eval=true -load=false`
+// `$ ./neuraldeep -n=2 -op=train -cost=crossEntropy -layers="784,300,10" -data=training -useMNIST=true -epochs=30 -size=10 -eta=0.5 -lambda=5.0 -preprocess=zScore -eval=true -load=false`
+// `$ ./neuraldeep -n=2 -op=train -cost=crossEntropy -layers="784,300,100,30,10" -data=training -useMNIST=true -epochs=30 -size=32 -eta=0.5 -batchnorm=true -eval=true -load=false`
 // `$ ./neuraldeep -n=2 -op=predict -cost=crossEntropy -layers="784,300,10" -data="0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,3,18,18,18,126,136,175,26,166,255,247,127,0,0,0,0,0,0,0,0,0,0,0,0,30,36,94,154,170,253,253,253,253,253,225,172,253,242,195,64,0,0,0,0,0,0,0,0,0,0,0,49,238,253,253,253,253,253,253,253,253,251,93,82,82,56,39,0,0,0,0,0,0,0,0,0,0,0,0,18,219,253,253,253,253,253,198,182,247,241,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,80,156,107,253,253,205,11,0,43,154,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,14,1,154,253,90,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,139,253,190,2,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,11,190,253,70,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,35,241,225,160,108,1,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,81,240,253,253,119,25,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,45,186,253,253,150,27,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,16,93,252,253,187,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,249,253,249,64,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,46,130,183,253,253,207,2,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,39,148,229,253,253,253,250,182,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,24,114,221,253,253,253,253,201,78,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,23,66,213,253,253,253,253,198,81,2,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,18,171,219,253,253,253,253,195,80,9,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,55,172,226,253,253,253,253,244,133,11,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,136,253,253,253,212,135,132,16,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0" -load=true -path="./data/saved/network2.json"`
+//
+// To look at the test images the network gets wrong, or to render the first inputs of a dataset:
+// `$ ./neuraldeep -n=2 -op=misclassified -layers="784,30,10" -data=test -mnist=true -count=300 -load=true -path="./data/saved/network2.json"`
+// `$ ./neuraldeep -n=2 -op=render -layers="784,30,10" -data=test -mnist=true -count=100`
+//
+// To draw the charts of the costs and accuracies per epoch, at the end of the training or afterwards from its JSONL log:
+// `$ ./neuraldeep -n=2 -op=train -layers="784,30,10" -data=training -mnist=true -epochs=30 -eta=0.5 -eval=true -charts=true`
+// `$ ./neuraldeep -op=plot -log="./data/saved/network2.jsonl"`
+//
+// To follow the training live in a browser at http://localhost:8080/:
+// `$ ./neuraldeep -n=2 -op=train -layers="784,30,10" -data=training -mnist=true -epochs=30 -eta=0.5 -eval=true -dashboard=":8080"`
+//
+// To expose the progress of the training to Prometheus at http://localhost:9090/metrics:
+// `$ ./neuraldeep -n=2 -op=train -layers="784,30,10" -data=training -mnist=true -epochs=30 -eta=0.5 -eval=true -metrics=":9090"`
+//
+// To train faster with float32 computations, the saved model storing float32 weights:
+// `$ ./neuraldeep -n=2 -op=train -layers="784,30,10" -data=training -mnist=true -epochs=30 -eta=0.5 -eval=true -precision=float32`
+//
+// To draw what the first hidden layer learned, each neuron's incoming weights being reshaped to a 28×28 heat-map:
+// `$ ./neuraldeep -n=2 -op=filters -layers="784,300,10" -load=true -path="./data/saved/network2.json"`
+//
+// To measure the training and inference throughputs of a network on synthetic data:
+// `$ ./neuraldeep -n=2 -op=bench -layers="784,100,10" -size=10 -eta=0.5 -precision=float32`
+//
+// To train a character-level language model on a text file, its last tenth being kept for evaluation:
+// `$ ./neuraldeep -op=charlm -src=./data/input.txt -cell=lstm -units=100 -bptt=25 -size=10 -epochs=50 -eta=0.1 -sample=200`
+//
+// To predict the digit drawn on a picture, which is size-normalized and centered like the MNIST images:
+// `$ ./neuraldeep -n=2 -op=predict -layers="784,30,10" -image=./digit.png -load=true -path="./data/saved/network2.json"`
+//
+// To tune the hyper-parameters of the second implementation, each candidate being trained with early stopping (-stop is required):
+// `$ ./neuraldeep -n=2 -op=tune -layers="784,30,10" -data=training -mnist=true -epochs=30 -stop=5 -search=grid -etas="0.1,0.5" -lambdas="1.0,5.0" -sizes="10" -hidden="30,100"`
+//
+// To cross-validate the second implementation on a CSV file whose lines start with the label:
+// `$ ./neuraldeep -n=2 -op=cv -layers="4,10,3" -src=./data/iris.csv -folds=5 -epochs=50 -size=5 -eta=0.5 -lambda=0.1`
+//
+// To serve a saved model over HTTP, eg. `$ curl -X POST -d '{"data":[0,0,...]}' http://localhost:8080/predict`:
+// `$ ./neuraldeep -n=2 -op=serve -layers="784,30,10" -path="./data/saved/network2.json" -addr=":8080" -watch=10s`
+//
+// To expand the MNIST training set like Michael Nielsen's expand_mnist.py, then train on distorted images drawn at each epoch:
+// `$ ./neuraldeep -n=2 -op=expand -layers="784,30,10" -data=training -mnist=true`
+// `$ ./neuraldeep -n=2 -op=train -layers="784,30,10" -data=training -mnist=true -epochs=30 -eta=0.5 -augment="shift,rotate,elastic" -seed=42 -eval=true`
 func main() {
 	// Parse command line arguments
 	n := flag.String("n", "1", "the network implementation to use: 1 | 2 | 3")
-	operation := flag.String("op", "", "operation to proceed: predict | test | train")
+	operation := flag.String("op", "", "operation to proceed: bench | charlm | cv | expand | filters | misclassified | plot | predict | render | serve | test | train | tune")
 	layersStr := flag.String("layers", "", "comma-separated list of number of neurons per layer (the first one being the size of the input layer)")
 	dataStr := flag.String("data", "", "a single data set to feed the first layer (a comma-separated list of float64), or the name of the MNIST set (test | training | validation)")
 	labelStr := flag.String("label", "", "the label/target of the passed value as a float64 number")
+	imagePath := flag.String("image", "", "a PNG, JPEG or GIF picture of a handwritten digit to predict (the layers flag should start with 784)")
 	src := flag.String("src", "", "the source file to use as input data")
 	useMNIST := flag.Bool("mnist", false, "set to true to use MNIST dataset (the layers flag should start with 784 and end with 10)")
 	epochs := flag.Int("epochs", 1, "number of epochs")
@@ -40,13 +111,61 @@ func main() {
 	evaluate := flag.Bool("eval", false, "set to `true` to add evaluation at each training epoch")
 	costFunction := flag.String("cost", "crossEntropy", "cost function: crossEntropy | quadratic")
 	lambda := flag.Float64("lambda", 0.0, "the regularization parameter")
+	initializerName := flag.String("init", "default", "weight initializer: default | large | xavier | he | lecun | orthogonal (network 2 only)")
+	regularizerName := flag.String("regularizer", "l2", "weight penalty: l1 | l2 | elasticNet (network 2 only)")
+	precision := flag.String("precision", network.FLOAT64, "floating-point precision of the computations and of the saved weights: float64 | float32 (network 2 only)")
+	batchNorm := flag.Bool("batchnorm", false, "set to `true` to add batch normalization to the hidden layers (network 2 only)")
+	dashboardAddr := flag.String("dashboard", "", "the TCP address of a live training dashboard, eg. \":8080\" (network 2 only)")
+	metricsAddr := flag.String("metrics", "", "the TCP address exposing the training metrics to Prometheus at /metrics, eg. \":9090\" (network 2 only)")
+	charts := flag.Bool("charts", false, "set to `true` to draw the charts of the costs and accuracies per epoch at the end of the training (network 2 only)")
+	logPath := flag.String("log", "./data/saved/network2.jsonl", "path to the JSONL training log written by the train operation and read by the plot operation")
+	diagnose := flag.Bool("gradients", false, "set to `true` to monitor the learning speed of each layer at each training epoch (network 2 only)")
+	dropoutStr := flag.String("dropout", "", "comma-separated list of dropout rates, one per hidden layer (network 2 only)")
+	seed := flag.Int64("seed", 0, "the seed of the random generators drawing the initial weights and the training order (0 to use the current time)")
+	stop := flag.Int("stop", 0, "stop training when the evaluation accuracy hasn't improved in that number of epochs (0 to disable, network 2 only, required by tune)")
+	preprocessStr := flag.String("preprocess", "", "comma-separated list of transformations fitted on the training data and applied to all inputs: minMax | zScore | pcaWhitening (network 2 only)")
+	whiteningEpsilon := flag.Float64("epsilon", preprocess.DEFAULT_WHITENING_EPSILON, "value added to the variance of each component by the pcaWhitening preprocessing, the larger the less its noisy low-variance components are amplified")
+	augmentStr := flag.String("augment", "", "comma-separated list of distortions applied to the training images: shift | rotate | elastic | noise (network 2 only)")
+	count := flag.Int("count", 100, "maximum number of inputs to render as images (0 for all)")
+	copies := flag.Int("copies", 0, "number of distorted copies of each image to add when expanding a dataset (0 for the four one-pixel shifts)")
+	k := flag.Int("folds", 5, "number of folds of the cross-validation")
+	search := flag.String("search", "grid", "hyper-parameters search strategy when tuning: grid | random")
+	trials := flag.Int("trials", 10, "number of candidates to draw with a random search")
+	etas := flag.String("etas", "0.1,0.5,1.0", "comma-separated list of learning rates to try when tuning")
+	lambdas := flag.String("lambdas", "0.0,1.0,5.0", "comma-separated list of regularization parameters to try when tuning")
+	miniBatchSizes := flag.String("sizes", "10", "comma-separated list of mini-batch sizes to try when tuning")
+	hiddenSizes := flag.String("hidden", "30,100", "comma-separated list of hidden layer widths to try when tuning")
+	addr := flag.String("addr", ":8080", "the TCP address the inference server listens on")
+	watch := flag.Duration("watch", server.DEFAULT_WATCH_INTERVAL, "interval between two checks of the served model file for a new version (0 to disable hot reload)")
+	maxBatch := flag.Int("maxbatch", server.DEFAULT_MAX_BATCH_SIZE, "maximum number of inputs of a batched prediction request to the inference server")
+	cell := flag.String("cell", recurrent.LSTM_CELL, "recurrent layer of the character-level language model: rnn | lstm")
+	units := flag.Int("units", 100, "number of hidden neurons of the recurrent layer of the language model")
+	bptt := flag.Int("bptt", recurrent.DEFAULT_TRUNCATION, "number of steps of the truncated backpropagation through time of the language model")
+	sampleLength := flag.Int("sample", 200, "number of characters generated by the language model after each epoch")
 
 	flag.Parse()
 
-	fmt.Printf("command to execute: $ ./neuraldeep -n=%s -op=%s -layers=%s -data=%s -label=%s -src=%s -mnist=%t -epochs=%d -size=%d -eta=%f -eval=%t -cost=%s -lambda=%f -load=%t -path=%s\n===\n",
-		*n, *operation, *layersStr, *dataStr, *labelStr, *src, *useMNIST, *epochs, *miniBatchSize, *eta, *evaluate, *costFunction, *lambda, *load, *pathToExisting)
+	fmt.Printf("command to execute: $ ./neuraldeep -n=%s -op=%s -layers=%s -data=%s -label=%s -image=%s -src=%s -mnist=%t -epochs=%d -size=%d -eta=%f -eval=%t -cost=%s -lambda=%f -init=%s -regularizer=%s -batchnorm=%t -precision=%s -gradients=%t -dashboard=%s -metrics=%s -charts=%t -log=%s -dropout=%s -seed=%d -stop=%d -preprocess=%s -epsilon=%g -augment=%s -copies=%d -count=%d -folds=%d -search=%s -trials=%d -etas=%s -lambdas=%s -sizes=%s -hidden=%s -addr=%s -maxbatch=%d -watch=%s -cell=%s -units=%d -bptt=%d -sample=%d -load=%t -path=%s\n===\n",
+		*n, *operation, *layersStr, *dataStr, *labelStr, *imagePath, *src, *useMNIST, *epochs, *miniBatchSize, *eta, *evaluate, *costFunction, *lambda, *initializerName, *regularizerName, *batchNorm, *precision, *diagnose, *dashboardAddr, *metricsAddr, *charts, *logPath, *dropoutStr, *seed, *stop, *preprocessStr, *whiteningEpsilon, *augmentStr, *copies, *count, *k, *search, *trials, *etas, *lambdas, *miniBatchSizes, *hiddenSizes, *addr, *maxBatch, *watch, *cell, *units, *bptt, *sampleLength, *load, *pathToExisting)
 	t0 := time.Now()
 
+	// Charts only need a training log
+	if *operation == "plot" {
+		fmt.Printf("loading the training log from %s\n", *logPath)
+		history, err := network.LoadHistory(*logPath)
+		if err != nil {
+			panic(err)
+		}
+		plot(history, strings.TrimSuffix(filepath.Base(*logPath), filepath.Ext(*logPath)))
+		return
+	}
+
+	// The language model is a recurrent network
+	if *operation == "charlm" {
+		trainLanguageModel(*src, *cell, *units, *bptt, *miniBatchSize, *epochs, *eta, *sampleLength, *seed)
+		return
+	}
+
 	// Choose the implementation
 	if *n == "1" {
 		// NETWORK.PY ###
@@ -100,7 +219,24 @@ func main() {
 				dataset = validation
 			}
 		} else {
-			if *dataStr != "" && *src == "" {
+			if *imagePath != "" {
+				// Read a digit from a picture
+				data, err := visual.ReadDigit(*imagePath)
+				if err != nil {
+					panic(err)
+				}
+				input := network.Input{
+					Data: data,
+				}
+				if *labelStr != "" {
+					label, err := strconv.ParseFloat(*labelStr, 64)
+					if err != nil {
+						panic(err)
+					}
+					input.Label = network.ToLabel(label, net.OutputSize())
+				}
+				dataset = append(dataset, &input)
+			} else if *dataStr != "" && *src == "" {
 				// Read from command line
 				dataArr := strings.Split(*dataStr, ",")
 				input := network.Input{
@@ -121,16 +257,23 @@ func main() {
 					input.Label = network.ToLabel(label, net.OutputSize())
 				}
 				dataset = append(dataset, &input)
-			} else if *src == "" {
+			} else if *src != "" {
 				// Retrieve from file
-				// TODO ####
-				fmt.Println("Not implemented yet")
+				ds, err := network.LoadCSV(*src, net.OutputSize(), sizes[0])
+				if err != nil {
+					panic(err)
+				}
+				dataset = ds
 			}
 		}
 
 		// Process the operation
 		t1 := time.Now()
 		switch *operation {
+		case "bench":
+			bench(sizes, *miniBatchSize, func(miniBatch network.Dataset) {
+				net.UpdateMiniBatch(miniBatch, *eta)
+			}, net.FeedForward)
 		case "predict":
 			fmt.Println("predicting...")
 			if *useMNIST {
@@ -146,11 +289,36 @@ func main() {
 				}
 				elapsed := time.Since(t1)
 				fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
-				fmt.Printf("target: #%d\n", int(dataset[0].Label.Value))
+				if dataset[0].Label != nil {
+					fmt.Printf("target: #%d\n", int(dataset[0].Label.Value))
+				}
 				for i := 0; i < c; i++ {
 					fmt.Printf("output #%d: %f\n", i, output.At(0, i))
 				}
+				fmt.Printf("prediction: #%d\n", network.Argmax(output))
 			}
+		case "filters":
+			_, weights := net.Parameters()
+			renderFilters(weights)
+		case "misclassified":
+			fmt.Println("looking for misclassified inputs...")
+			errs := net.Misclassified(dataset)
+			elapsed := time.Since(t1)
+			fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
+			renderMisclassified(errs, dataset, *count)
+		case "render":
+			renderInputs(dataset, *count)
+		case "serve":
+			serve(*pathToExisting, func(path string) (*server.Model, error) {
+				n, err := network.Init(sizes)
+				if err != nil {
+					return nil, err
+				}
+				if err := network.Load(n, path); err != nil {
+					return nil, err
+				}
+				return server.FromNetwork1(n), nil
+			}, *addr, *maxBatch, *watch)
 		case "test":
 			fmt.Println("testing...")
 			sum := net.Evaluate(dataset)
@@ -198,6 +366,64 @@ func main() {
 		default:
 			panic("invalid cost function")
 		}
+		var dropout []float64
+		if *dropoutStr != "" {
+			dropout = parseFloats(*dropoutStr)
+		}
+		var augmenter *augment.Augmenter
+		if *augmentStr != "" {
+			a, err := augment.New(strings.Split(*augmentStr, ","))
+			if err != nil {
+				panic(err)
+			}
+			augmenter = &a
+		}
+		var pipeline preprocess.Pipeline
+		// newPipeline creates an unfitted preprocessing pipeline with the settings passed on the command line
+		newPipeline := func() (preprocess.Pipeline, error) {
+			return preprocess.NewPipeline(strings.Split(*preprocessStr, ","), *whiteningEpsilon)
+		}
+		if *preprocessStr != "" {
+			if augmenter != nil && *operation != "expand" {
+				panic(errors.New("on-the-fly augmentation works on raw images: expand the dataset first to combine it with preprocessing"))
+			}
+			p, err := newPipeline()
+			if err != nil {
+				panic(err)
+			}
+			pipeline = p
+		}
+		// newNetwork creates an untrained network with the settings passed on the command line, whose initial weights
+		// are drawn from the seed if one is passed
+		newNetwork := func(sizes []int) (*network.Network2, error) {
+			var rng *rand.Rand
+			if *seed != 0 {
+				rng = rand.New(rand.NewSource(*seed))
+			}
+			n, err := network.InitialWith(sizes, *initializerName, rng, cf)
+			if err != nil {
+				return nil, err
+			}
+			if n.Regularizer, err = regularization.New(*regularizerName); err != nil {
+				return nil, err
+			}
+			if *seed != 0 {
+				n.Seed(*seed)
+			}
+			if err := n.SetDropout(dropout); err != nil {
+				return nil, err
+			}
+			n.SetBatchNorm(*batchNorm)
+			if err := n.SetPrecision(*precision); err != nil {
+				return nil, err
+			}
+			n.SetEarlyStopping(*stop)
+			if augmenter != nil {
+				n.SetAugmentation(augmenter.Transform)
+			}
+			n.SetPreprocessing(pipeline)
+			return n, nil
+		}
 		if *load {
 			fmt.Printf("loading from %s\n", *pathToExisting)
 			n, err := network.Initial(sizes, cf)
@@ -207,9 +433,31 @@ func main() {
 			if err := n.Load(*pathToExisting); err != nil {
 				panic(err)
 			}
+			if isFlagPassed("regularizer") {
+				if n.Regularizer, err = regularization.New(*regularizerName); err != nil {
+					panic(err)
+				}
+			}
+			if *seed != 0 {
+				n.Seed(*seed)
+			}
+			if dropout != nil {
+				if err := n.SetDropout(dropout); err != nil {
+					panic(err)
+				}
+			}
+			if isFlagPassed("precision") {
+				if err := n.SetPrecision(*precision); err != nil {
+					panic(err)
+				}
+			}
+			n.SetEarlyStopping(*stop)
+			if augmenter != nil {
+				n.SetAugmentation(augmenter.Transform)
+			}
 			net = n
 		} else {
-			n, err := network.Initial(sizes, cf)
+			n, err := newNetwork(sizes)
 			if err != nil {
 				panic(err)
 			}
@@ -221,11 +469,13 @@ func main() {
 		// Get the input data
 		dataset := network.Dataset{}
 		evalset := network.Dataset{}
+		validset := network.Dataset{}
 		if *useMNIST {
 			training, validation, test, err := network.LoadData()
 			if err != nil {
 				panic(err)
 			}
+			validset = validation
 			switch *dataStr {
 			case "test":
 				dataset = test
@@ -238,7 +488,24 @@ func main() {
 				evalset = test
 			}
 		} else {
-			if *dataStr != "" && *src == "" {
+			if *imagePath != "" {
+				// Read a digit from a picture
+				data, err := visual.ReadDigit(*imagePath)
+				if err != nil {
+					panic(err)
+				}
+				input := network.Input{
+					Data: data,
+				}
+				if *labelStr != "" {
+					label, err := strconv.ParseFloat(*labelStr, 64)
+					if err != nil {
+						panic(err)
+					}
+					input.Label = network.ToLabel(label, net.OutputSize())
+				}
+				dataset = append(dataset, &input)
+			} else if *dataStr != "" && *src == "" {
 				// Read from command line
 				dataArr := strings.Split(*dataStr, ",")
 				input := network.Input{
@@ -259,16 +526,38 @@ func main() {
 					input.Label = network.ToLabel(label, net.OutputSize())
 				}
 				dataset = append(dataset, &input)
-			} else if *src == "" {
+			} else if *src != "" {
 				// Retrieve from file
-				// TODO ####
-				fmt.Println("Not implemented yet")
+				ds, err := network.LoadCSV(*src, net.OutputSize(), sizes[0])
+				if err != nil {
+					panic(err)
+				}
+				dataset = ds
 			}
 		}
 
+		// Preprocess the input data, keeping the raw one to render it.
+		// Cross-validation fits a pipeline of its own on the training data of each fold instead.
+		raw := dataset
+		if *operation != "expand" && *operation != "cv" {
+			if pipeline != nil && (*operation == "train" || *operation == "tune") {
+				if err := dataset.Fit(pipeline); err != nil {
+					panic(err)
+				}
+				net.SetPreprocessing(pipeline)
+			}
+			dataset = net.Preprocess(dataset)
+			evalset = net.Preprocess(evalset)
+			validset = net.Preprocess(validset)
+		}
+
 		// Process the operation
 		t1 := time.Now()
 		switch *operation {
+		case "bench":
+			bench(sizes, *miniBatchSize, func(miniBatch network.Dataset) {
+				net.UpdateMiniBatch(miniBatch, *eta, *lambda, BENCH_SAMPLES)
+			}, net.FeedForward)
 		case "predict":
 			fmt.Println("predicting...")
 			if *useMNIST {
@@ -284,19 +573,191 @@ func main() {
 				}
 				elapsed := time.Since(t1)
 				fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
-				fmt.Printf("target: #%d\n", int(dataset[0].Label.Value))
+				if dataset[0].Label != nil {
+					fmt.Printf("target: #%d\n", int(dataset[0].Label.Value))
+				}
 				for i := 0; i < c; i++ {
 					fmt.Printf("output #%d: %f\n", i, output.At(0, i))
 				}
+				fmt.Printf("prediction: #%d\n", network.Argmax(output))
 			}
+		case "filters":
+			_, weights := net.Parameters()
+			renderFilters(weights)
+		case "misclassified":
+			fmt.Println("looking for misclassified inputs...")
+			errs := net.Misclassified(dataset)
+			elapsed := time.Since(t1)
+			fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
+			renderMisclassified(errs, raw, *count)
+		case "render":
+			renderInputs(raw, *count)
+		case "serve":
+			serve(*pathToExisting, func(path string) (*server.Model, error) {
+				n, err := network.Initial(sizes, cf)
+				if err != nil {
+					return nil, err
+				}
+				if err := n.Load(path); err != nil {
+					return nil, err
+				}
+				return server.FromNetwork2(n), nil
+			}, *addr, *maxBatch, *watch)
 		case "test":
 			fmt.Println("Not implemented")
+		case "cv":
+			fmt.Println("cross-validating...")
+			var rng *rand.Rand
+			if *seed != 0 {
+				rng = rand.New(rand.NewSource(*seed))
+			}
+			folds, err := dataset.StratifiedKFold(*k, rng)
+			if err != nil {
+				panic(err)
+			}
+			config := tuning.Config{
+				Sizes:         sizes,
+				Epochs:        *epochs,
+				EarlyStopping: *stop,
+				New:           newNetwork,
+			}
+			if pipeline != nil {
+				config.Preprocessing = newPipeline
+			}
+			candidate := tuning.Candidate{
+				Eta:           *eta,
+				Lambda:        *lambda,
+				MiniBatchSize: *miniBatchSize,
+			}
+			cv, err := tuning.CrossValidate(folds, candidate, config)
+			if err != nil {
+				panic(err)
+			}
+			elapsed := time.Since(t1)
+			fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
+			for i, trial := range cv.Trials {
+				fmt.Printf("fold %d: %d / %d [accuracy=%.4f, cost=%.4f, epoch=%d / %d]\n", i+1, trial.Accuracy, len(folds[i].Validation), cv.Accuracies[i], cv.Costs[i], trial.BestEpoch, trial.Epochs)
+			}
+			fmt.Printf("accuracy: %.4f ± %.4f\n", cv.MeanAccuracy, cv.StdDevAccuracy)
+			fmt.Printf("cost: %.4f ± %.4f\n", cv.MeanCost, cv.StdDevCost)
+		case "expand":
+			fmt.Println("expanding...")
+			var expanded network.Dataset
+			var err error
+			if *copies > 0 {
+				if augmenter == nil {
+					panic(errors.New("the augment flag is required to draw distorted copies"))
+				}
+				rngSeed := *seed
+				if rngSeed == 0 {
+					rngSeed = time.Now().UnixNano()
+				}
+				expanded, err = augment.Expand(dataset, *augmenter, *copies, rand.New(rand.NewSource(rngSeed)))
+			} else {
+				expanded, err = augment.ExpandShifts(dataset)
+			}
+			if err != nil {
+				panic(err)
+			}
+			elapsed := time.Since(t1)
+			fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
+			fmt.Printf("saving %d inputs to ./data/loaded/mnist_expanded.csv\n", len(expanded))
+			if err := network.SaveCSV(expanded, "./data/loaded/mnist_expanded.csv"); err != nil {
+				panic(err)
+			}
+			elapsed = time.Since(t0)
+			fmt.Printf("terminated in %f s\n", elapsed.Seconds())
+		case "tune":
+			fmt.Println("tuning...")
+			space := tuning.Space{
+				Etas:           parseFloats(*etas),
+				Lambdas:        parseFloats(*lambdas),
+				MiniBatchSizes: parseInts(*miniBatchSizes),
+				HiddenSizes:    parseInts(*hiddenSizes),
+			}
+			var candidates []tuning.Candidate
+			var err error
+			switch *search {
+			case tuning.GRID_SEARCH:
+				candidates, err = space.Grid()
+			case tuning.RANDOM_SEARCH:
+				rngSeed := *seed
+				if rngSeed == 0 {
+					rngSeed = time.Now().UnixNano()
+				}
+				candidates, err = space.Random(*trials, rand.New(rand.NewSource(rngSeed)))
+			default:
+				err = errors.New("invalid search strategy")
+			}
+			if err != nil {
+				panic(err)
+			}
+			if len(validset) == 0 {
+				panic(errors.New("tuning needs a validation set"))
+			}
+			if *dataStr == "validation" {
+				panic(errors.New("tuning can't train on the validation set it ranks the candidates with"))
+			}
+			if *stop <= 0 {
+				panic(errors.New("tuning trains each candidate with early stopping: pass a positive -stop"))
+			}
+			config := tuning.Config{
+				Sizes:         sizes,
+				Epochs:        *epochs,
+				EarlyStopping: *stop,
+				New:           newNetwork,
+			}
+			leaderboard, best, err := tuning.Search(candidates, dataset, validset, config)
+			if err != nil {
+				panic(err)
+			}
+			elapsed := time.Since(t1)
+			fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
+			for i, trial := range leaderboard {
+				fmt.Printf("#%d: %d / %d [eta=%f, lambda=%f, size=%d, hidden=%d, cost=%.4f, epoch=%d / %d]\n", i+1, trial.Accuracy, len(validset), trial.Eta, trial.Lambda, trial.MiniBatchSize, trial.Hidden, trial.Cost, trial.BestEpoch, trial.Epochs)
+			}
+			fmt.Println("saving to ./data/saved/tuning/")
+			if err := tuning.WriteLeaderboard(leaderboard, len(validset), "./data/saved/tuning/leaderboard.csv"); err != nil {
+				panic(err)
+			}
+			if err := best.Save("./data/saved/tuning/best.json"); err != nil {
+				panic(err)
+			}
+			elapsed = time.Since(t0)
+			fmt.Printf("terminated in %f s\n", elapsed.Seconds())
 		case "train":
 			fmt.Println("training...")
+			evaluation := network.Dataset{}
 			if *evaluate {
-				net.SGD(dataset, *epochs, *miniBatchSize, *eta, *lambda, evalset, true, true, true, true)
-			} else {
-				net.SGD(dataset, *epochs, *miniBatchSize, *eta, *lambda, network.Dataset{})
+				evaluation = evalset
+			}
+			monitorTraining := *evaluate || *charts || *dashboardAddr != "" || *metricsAddr != ""
+			var board *dashboard.Dashboard
+			if *dashboardAddr != "" {
+				board = dashboard.New(fmt.Sprintf("Network %s with %s", *n, net.Cost.GetName()))
+				net.OnEpoch(board.Publish)
+				go func() {
+					if err := board.ListenAndServe(*dashboardAddr); err != nil {
+						panic(err)
+					}
+				}()
+				fmt.Printf("live dashboard on %s\n", *dashboardAddr)
+			}
+			if *metricsAddr != "" {
+				registry := metrics.NewRegistry()
+				net.OnEpoch(metrics.NewTraining(registry).Observe)
+				mux := http.NewServeMux()
+				mux.Handle("GET /metrics", registry.Handler())
+				go func() {
+					if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+						panic(err)
+					}
+				}()
+				fmt.Printf("training metrics on %s/metrics\n", *metricsAddr)
+			}
+			evaluationCost, evaluationAccuracy, trainingCost, trainingAccuracy, gradients := net.SGD(dataset, *epochs, *miniBatchSize, *eta, *lambda, evaluation, *evaluate, *evaluate, monitorTraining, monitorTraining, *diagnose)
+			if *diagnose {
+				fmt.Print(network.LearningSpeedTable(gradients))
 			}
 			elapsed := time.Since(t1)
 			fmt.Printf("elapsed: %d ms\n", elapsed.Milliseconds())
@@ -304,8 +765,24 @@ func main() {
 			if err := net.Save("./data/saved/network2.json"); err != nil {
 				panic(err)
 			}
+			if history := network.History(evaluationCost, evaluationAccuracy, trainingCost, trainingAccuracy, len(dataset), len(evaluation)); len(history) > 0 {
+				fmt.Printf("saving the training log to %s\n", *logPath)
+				if err := network.SaveHistory(history, *logPath); err != nil {
+					panic(err)
+				}
+				if *charts {
+					plot(history, fmt.Sprintf("Network %s with %s", *n, net.Cost.GetName()))
+				}
+			}
 			elapsed = time.Since(t0)
 			fmt.Printf("terminated in %f s\n", elapsed.Seconds())
+			if board != nil {
+				board.Finish()
+				fmt.Println("press Ctrl+C to stop the dashboard")
+				interrupt := make(chan os.Signal, 1)
+				signal.Notify(interrupt, os.Interrupt)
+				<-interrupt
+			}
 		default:
 			fmt.Println("invalid operation: ", *operation)
 		}
@@ -313,3 +790,204 @@ func main() {
 		fmt.Println("not implemented yet")
 	}
 }
+
+// bench measures the throughput of the network of the passed sizes on BENCH_SAMPLES synthetic inputs, first training it
+// with mini-batches of 'miniBatchSize' inputs then predicting them one at a time, each phase lasting about BENCH_DURATION.
+func bench(sizes []int, miniBatchSize int, train func(miniBatch network.Dataset), predict func(x mat.Vector) mat.Matrix) {
+	if miniBatchSize <= 0 || miniBatchSize > BENCH_SAMPLES {
+		panic(fmt.Errorf("invalid mini-batch size: %d", miniBatchSize))
+	}
+	data := network.Synthetic(BENCH_SAMPLES, sizes[0], sizes[len(sizes)-1], rand.New(rand.NewSource(1)))
+	vectors := make([]mat.Vector, len(data))
+	for i, input := range data {
+		vectors[i] = input.ToVector()
+	}
+	batches := len(data) / miniBatchSize
+	fmt.Printf("benchmarking %v on %d synthetic inputs [size=%d, gomaxprocs=%d]\n", sizes, len(data), miniBatchSize, runtime.GOMAXPROCS(0))
+	measure := func(phase string, step func(i int) (samples int)) {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		samples := 0
+		t := time.Now()
+		for i := 0; time.Since(t) < BENCH_DURATION; i++ {
+			samples += step(i)
+		}
+		elapsed := time.Since(t)
+		runtime.ReadMemStats(&after)
+		fmt.Printf("%s: %.0f samples/s, %.1f µs/sample, %.1f allocs/sample, %.0f B/sample\n", phase,
+			float64(samples)/elapsed.Seconds(), float64(elapsed.Microseconds())/float64(samples),
+			float64(after.Mallocs-before.Mallocs)/float64(samples), float64(after.TotalAlloc-before.TotalAlloc)/float64(samples))
+	}
+	measure("training", func(i int) int {
+		k := (i % batches) * miniBatchSize
+		train(data[k : k+miniBatchSize])
+		return miniBatchSize
+	})
+	measure("inference", func(i int) int {
+		predict(vectors[i%len(vectors)])
+		return 1
+	})
+}
+
+// plot saves the charts of the costs and accuracies per epoch of the training history.
+func plot(history []network.Epoch, title string) {
+	cost, accuracy := visual.TrainingCharts(history, title)
+	for i, chart := range []visual.LineChart{cost, accuracy} {
+		if len(chart.Series) == 0 {
+			continue
+		}
+		name := []string{"cost", "accuracy"}[i]
+		path := fmt.Sprintf("./data/saved/charts/%s.svg", name)
+		fmt.Printf("saving the %s chart to %s\n", name, path)
+		if err := chart.SaveSVG(path); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// renderFilters saves the incoming weights of each layer following a square one as a grid of heat-maps.
+func renderFilters(weights []mat.Matrix) {
+	layers := visual.SquareLayers(weights)
+	if len(layers) == 0 {
+		panic(errors.New("no layer has a perfect square input dimension"))
+	}
+	for _, l := range layers {
+		grid, err := visual.Filters(weights[l], 2, 0)
+		if err != nil {
+			panic(err)
+		}
+		path := fmt.Sprintf("./data/saved/visual/filters_layer%d.png", l+1)
+		fmt.Printf("saving the filters of layer %d to %s\n", l+1, path)
+		if err := visual.SavePNG(grid, path); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// renderInputs saves the first 'count' inputs of the dataset as PNG images.
+func renderInputs(ds network.Dataset, count int) {
+	if count > 0 && count < len(ds) {
+		ds = ds[:count]
+	}
+	fmt.Printf("saving %d inputs to ./data/saved/visual/inputs/\n", len(ds))
+	if err := visual.WriteTiles(ds, "./data/saved/visual/inputs/", 1); err != nil {
+		panic(err)
+	}
+}
+
+// renderMisclassified saves a contact sheet of the first 'count' misclassified inputs, drawn from the 'raw' dataset.
+func renderMisclassified(errs []network.Misclassification, raw network.Dataset, count int) {
+	fmt.Printf("nbOfMisclassifiedInputs: %d / %d\n", len(errs), len(raw))
+	if len(errs) == 0 {
+		return
+	}
+	if count > 0 && count < len(errs) {
+		errs = errs[:count]
+	}
+	for i := range errs {
+		errs[i].Input = raw[errs[i].Index]
+	}
+	sheet, err := visual.ContactSheet(errs, visual.SHEET_COLUMNS)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("saving to ./data/saved/visual/misclassified.png")
+	if err := visual.SavePNG(sheet, "./data/saved/visual/misclassified.png"); err != nil {
+		panic(err)
+	}
+}
+
+// serve exposes the model saved at 'path' through the HTTP inference server until it fails,
+// reloading it every 'watch' interval if it changed.
+func serve(path string, load server.Loader, addr string, maxBatchSize int, watch time.Duration) {
+	s, err := server.NewFromFile(path, load)
+	if err != nil {
+		panic(err)
+	}
+	s.MaxBatchSize = maxBatchSize
+	model := s.Model()
+	fmt.Printf("serving network %s %v on %s [version=%d, checksum=%s]\n", model.Network, model.Sizes, addr, model.Version, model.Checksum)
+	if watch > 0 {
+		go s.Watch(context.Background(), watch, os.Stdout)
+	}
+	if err := s.ListenAndServe(addr); err != nil {
+		panic(err)
+	}
+}
+
+// trainLanguageModel trains a character-level language model on the text file at 'path', split into 'streams' sequences
+// trained together, and prints a sample of 'length' generated characters after each epoch. The last tenth of the text
+// is kept for evaluation. The initial weights and the samples are drawn from 'seed', or from the current time if it's 0.
+func trainLanguageModel(path, cell string, units, bptt, streams, epochs int, eta float64, length int, seed int64) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	runes := []rune(string(content))
+	split := len(runes) * 9 / 10
+	vocab := recurrent.NewVocabulary(string(runes))
+	training := vocab.Sequences(string(runes[:split]), streams)
+	evaluation := vocab.Sequences(string(runes[split:]), 1)
+	if len(training) == 0 || len(training[0]) == 0 {
+		panic(errors.New("the text is too short for the number of sequences"))
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	var l recurrent.Layer
+	switch cell {
+	case recurrent.RNN_CELL:
+		l = recurrent.NewRNN(vocab.Size(), units, rng)
+	case recurrent.LSTM_CELL:
+		l = recurrent.NewLSTM(vocab.Size(), units, rng)
+	default:
+		panic(errors.New("unavailable recurrent layer"))
+	}
+	net := recurrent.New(l, layer.NewSequential(layer.LogLikelihoodLoss{}, layer.NewDense(units, vocab.Size(), rng), &layer.Softmax{}))
+	net.Truncation = bptt
+	net.Rand = rng
+	fmt.Printf("training a %s language model [vocabulary=%d, characters=%d, sequences=%d×%d]\n", cell, vocab.Size(), len(runes), len(training), len(training[0]))
+	t := time.Now()
+	for j := 0; j < epochs; j++ {
+		cost := net.UpdateMiniBatch(training, eta)
+		fmt.Printf("epoch %d: training cost %f, evaluation cost %f, elapsed %d ms\n", j+1, cost, net.TotalCost(evaluation), time.Since(t).Milliseconds())
+		fmt.Printf("---\n%s\n---\n", vocab.Sample(net, "", length, SAMPLE_TEMPERATURE, rng))
+	}
+}
+
+// isFlagPassed tells whether the flag 'name' was explicitly set on the command line.
+func isFlagPassed(name string) bool {
+	found := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+// parseFloats returns the numbers of the passed comma-separated list, panicking if any isn't a valid float64.
+func parseFloats(list string) (values []float64) {
+	for _, str := range strings.Split(list, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		if err != nil {
+			panic(err)
+		}
+		values = append(values, v)
+	}
+	return
+}
+
+// parseInts returns the numbers of the passed comma-separated list, panicking if any isn't a valid integer.
+func parseInts(list string) (values []int) {
+	for _, str := range strings.Split(list, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(str))
+		if err != nil {
+			panic(err)
+		}
+		values = append(values, v)
+	}
+	return
+}
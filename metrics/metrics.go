@@ -0,0 +1,260 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A minimal implementation of the Prometheus metric types and of their text exposition format (version 0.0.4),
+// see https://prometheus.io/docs/instrumenting/exposition_formats/, so that the cluster can scrape the binary
+// without adding the Prometheus client library to the dependencies.
+
+const (
+	COUNTER   = "counter"
+	GAUGE     = "gauge"
+	HISTOGRAM = "histogram"
+
+	CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// DEFAULT_BUCKETS are the upper bounds in seconds of the latency histograms.
+var DEFAULT_BUCKETS = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+//--- TYPES
+
+// Registry holds the metrics to expose, in their registration order.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*Metric
+}
+
+// Metric is a family of series sharing a name, a type and label names, each series having its own label values.
+type Metric struct {
+	Name    string
+	Help    string
+	Type    string
+	Labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*Series
+}
+
+// Series is a single time series, ie. the value of a metric for a set of label values.
+type Series struct {
+	labelValues []string
+	counter     bool
+	bounds      []float64
+	mu          sync.Mutex
+	value       float64
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+//--- METHODS
+
+// Counter registers a monotonically increasing metric.
+func (r *Registry) Counter(name, help string, labels ...string) *Metric {
+	return r.register(&Metric{Name: name, Help: help, Type: COUNTER, Labels: labels})
+}
+
+// Gauge registers a metric that can go up and down.
+func (r *Registry) Gauge(name, help string, labels ...string) *Metric {
+	return r.register(&Metric{Name: name, Help: help, Type: GAUGE, Labels: labels})
+}
+
+// Handler returns the HTTP handler writing the registry in the text exposition format, usually served at `/metrics`.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", CONTENT_TYPE)
+		r.WriteText(w)
+	})
+}
+
+// Histogram registers a metric counting observations in buckets of the passed increasing upper bounds.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Metric {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: the buckets of %s aren't sorted", name))
+	}
+	return r.register(&Metric{Name: name, Help: help, Type: HISTOGRAM, Labels: labels, buckets: buckets})
+}
+
+// WriteText writes all the series of the registry in the text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]*Metric(nil), r.metrics...)
+	r.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+func (r *Registry) register(m *Metric) *Metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.metrics {
+		if existing.Name == m.Name {
+			panic(fmt.Sprintf("metrics: %s is already registered", m.Name))
+		}
+	}
+	m.series = make(map[string]*Series)
+	r.metrics = append(r.metrics, m)
+	return m
+}
+
+// With returns the series of the passed label values, in the order of the metric's label names, creating it if need be.
+func (m *Metric) With(values ...string) *Series {
+	if len(values) != len(m.Labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", m.Name, len(m.Labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &Series{
+			labelValues: append([]string(nil), values...),
+			counter:     m.Type == COUNTER,
+		}
+		if m.Type == HISTOGRAM {
+			s.bounds = m.buckets
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+func (m *Metric) writeText(w io.Writer) {
+	m.mu.Lock()
+	series := make([]*Series, 0, len(m.series))
+	for _, s := range m.series {
+		series = append(series, s)
+	}
+	m.mu.Unlock()
+	if len(series) == 0 {
+		return
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return strings.Join(series[i].labelValues, "\xff") < strings.Join(series[j].labelValues, "\xff")
+	})
+	fmt.Fprintf(w, "# HELP %s %s\n", m.Name, escape(m.Help, false))
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.Name, m.Type)
+	for _, s := range series {
+		s.mu.Lock()
+		if m.Type == HISTOGRAM {
+			var cumulative uint64
+			for i, bound := range m.buckets {
+				cumulative += s.counts[i]
+				fmt.Fprintf(w, "%s_bucket%s %d\n", m.Name, labelSet(m.Labels, s.labelValues, "le", formatValue(bound)), cumulative)
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.Name, labelSet(m.Labels, s.labelValues, "le", "+Inf"), s.count)
+			fmt.Fprintf(w, "%s_sum%s %s\n", m.Name, labelSet(m.Labels, s.labelValues), formatValue(s.sum))
+			fmt.Fprintf(w, "%s_count%s %d\n", m.Name, labelSet(m.Labels, s.labelValues), s.count)
+		} else {
+			fmt.Fprintf(w, "%s%s %s\n", m.Name, labelSet(m.Labels, s.labelValues), formatValue(s.value))
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Add increases the value of a counter or gauge by 'v', which must be non-negative for a counter.
+func (s *Series) Add(v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts != nil {
+		panic("metrics: use Observe() on a histogram")
+	}
+	if s.counter && v < 0 {
+		panic("metrics: a counter can't decrease")
+	}
+	s.value += v
+}
+
+// Inc increases the value of a counter or gauge by one.
+func (s *Series) Inc() {
+	s.Add(1)
+}
+
+// Observe adds the value 'v' to the histogram.
+func (s *Series) Observe(v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sum += v
+	s.count++
+	for i := range s.counts {
+		if v <= s.bounds[i] {
+			s.counts[i]++
+			return
+		}
+	}
+}
+
+// Set replaces the value of a gauge.
+func (s *Series) Set(v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value = v
+}
+
+// Value returns the current value of a counter or gauge, or the number of observations of a histogram.
+func (s *Series) Value() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts != nil {
+		return float64(s.count)
+	}
+	return s.value
+}
+
+//--- FUNCTIONS
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func escape(s string, quoted bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quoted {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelSet formats the labels as `{name="value",...}`, followed by the extra name/value pairs if any.
+func labelSet(names, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var pairs []string
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escape(values[i], true)))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], extra[i+1]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
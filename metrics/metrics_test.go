@@ -0,0 +1,89 @@
+package metrics_test
+
+import (
+	"net/http/httptest"
+	"neuraldeep/metrics"
+	"neuraldeep/network"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestRegistry ...
+func TestRegistry(t *testing.T) {
+	r := metrics.NewRegistry()
+	requests := r.Counter("requests_total", "Number of requests.", "path", "code")
+	temperature := r.Gauge("temperature", "Current\ntemperature.")
+	latency := r.Histogram("latency_seconds", "Latency.", []float64{0.1, 1})
+	r.Gauge("unused", "Never set.")
+
+	requests.With("/b", "200").Inc()
+	requests.With("/a", "200").Add(2)
+	requests.With(`/"quoted"`, "500").Inc()
+	temperature.With().Set(-1.5)
+	latency.With().Observe(0.05)
+	latency.With().Observe(0.5)
+	latency.With().Observe(3)
+	assert.Equal(t, requests.With("/a", "200").Value(), 2.0)
+
+	var b strings.Builder
+	assert.NilError(t, r.WriteText(&b))
+	expected := `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{path="/\"quoted\"",code="500"} 1
+requests_total{path="/a",code="200"} 2
+requests_total{path="/b",code="200"} 1
+# HELP temperature Current\ntemperature.
+# TYPE temperature gauge
+temperature -1.5
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 3.55
+latency_seconds_count 3
+`
+	assert.Equal(t, b.String(), expected)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, rec.Header().Get("Content-Type"), metrics.CONTENT_TYPE)
+	assert.Equal(t, rec.Body.String(), expected)
+
+	assert.Assert(t, panics(func() { requests.With("/a").Inc() }))
+	assert.Assert(t, panics(func() { requests.With("/a", "200").Add(-1) }))
+	assert.Assert(t, panics(func() { r.Gauge("temperature", "Duplicate.") }))
+}
+
+// TestTraining ...
+func TestTraining(t *testing.T) {
+	r := metrics.NewRegistry()
+	training := metrics.NewTraining(r)
+	accuracy := 0.9
+	for epoch := 1; epoch <= 2; epoch++ {
+		training.Observe(network.Epoch{Epoch: epoch, EvaluationAccuracy: &accuracy, Duration: 2, Samples: 100, Eta: 0.5})
+	}
+
+	var b strings.Builder
+	r.WriteText(&b)
+	for _, line := range []string{
+		"neuraldeep_training_epoch 2",
+		"neuraldeep_training_samples_total 200",
+		"neuraldeep_training_samples_per_second 50",
+		"neuraldeep_training_eta 0.5",
+		`neuraldeep_training_accuracy{dataset="evaluation"} 0.9`,
+	} {
+		assert.Assert(t, strings.Contains(b.String(), line+"\n"), line)
+	}
+	assert.Assert(t, !strings.Contains(b.String(), "neuraldeep_training_cost"))
+}
+
+func panics(f func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	f()
+	return
+}
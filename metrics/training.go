@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"neuraldeep/network"
+)
+
+//--- TYPES
+
+// Training exposes the progress of a training, updated at the end of each epoch by Observe().
+type Training struct {
+	epoch            *Metric
+	duration         *Metric
+	samples          *Metric
+	samplesPerSecond *Metric
+	eta              *Metric
+	cost             *Metric
+	accuracy         *Metric
+}
+
+//--- METHODS
+
+// Observe updates the metrics with the passed epoch. Its signature makes it a network.EpochHook.
+func (t *Training) Observe(e network.Epoch) {
+	t.epoch.With().Set(float64(e.Epoch))
+	t.eta.With().Set(e.Eta)
+	if e.Duration > 0 {
+		t.duration.With().Set(e.Duration)
+		t.samplesPerSecond.With().Set(float64(e.Samples) / e.Duration)
+	}
+	t.samples.With().Add(float64(e.Samples))
+	if e.TrainingCost != nil {
+		t.cost.With("training").Set(*e.TrainingCost)
+	}
+	if e.EvaluationCost != nil {
+		t.cost.With("evaluation").Set(*e.EvaluationCost)
+	}
+	if e.TrainingAccuracy != nil {
+		t.accuracy.With("training").Set(*e.TrainingAccuracy)
+	}
+	if e.EvaluationAccuracy != nil {
+		t.accuracy.With("evaluation").Set(*e.EvaluationAccuracy)
+	}
+}
+
+//--- FUNCTIONS
+
+// NewTraining registers the training metrics in the passed registry.
+func NewTraining(r *Registry) *Training {
+	return &Training{
+		epoch:            r.Gauge("neuraldeep_training_epoch", "Last completed training epoch."),
+		duration:         r.Gauge("neuraldeep_training_epoch_duration_seconds", "Duration of the gradient descent of the last epoch."),
+		samples:          r.Counter("neuraldeep_training_samples_total", "Number of training inputs the gradient descent went through."),
+		samplesPerSecond: r.Gauge("neuraldeep_training_samples_per_second", "Training throughput of the last epoch."),
+		eta:              r.Gauge("neuraldeep_training_eta", "Current learning rate."),
+		cost:             r.Gauge("neuraldeep_training_cost", "Cost at the end of the last epoch.", "dataset"),
+		accuracy:         r.Gauge("neuraldeep_training_accuracy", "Fraction of correct results at the end of the last epoch.", "dataset"),
+	}
+}
@@ -0,0 +1,287 @@
+package network
+
+import (
+	"errors"
+	"math"
+	"neuraldeep/activation"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Batch normalization (Ioffe & Szegedy, 2015) standardizes the weighted inputs `z` of each hidden neuron over the mini-batch,
+// then scales and shifts them with the learned parameters `γ` and `β` before applying the sigmoid, ie. `a = σ(γ ẑ + β)`.
+// During training, the statistics of the mini-batch are used and running averages of them are kept for inference.
+// Note that the biases of a normalized layer are made useless by the mean subtraction, `β` playing their role.
+
+const (
+	DEFAULT_BATCH_NORM_MOMENTUM = 0.9
+	DEFAULT_BATCH_NORM_EPSILON  = 1e-5
+)
+
+//--- TYPES
+
+// BatchNorm holds the parameters of the batch normalization of a hidden layer.
+type BatchNorm struct {
+	Gamma       []float64 `json:"gamma"`
+	Beta        []float64 `json:"beta"`
+	RunningMean []float64 `json:"runningMean"`
+	RunningVar  []float64 `json:"runningVar"`
+	Momentum    float64   `json:"momentum"`
+	Epsilon     float64   `json:"epsilon"`
+}
+
+// batchCache holds the intermediate values of a forward pass over a mini-batch needed by the backward pass.
+type batchCache struct {
+	activations []mat.Matrix // one m×n matrix per layer, starting with the input layer
+	ys          []mat.Matrix // the sigmoid inputs, ie. the weighted inputs possibly normalized
+	xHats       []mat.Matrix // the normalized weighted inputs of each hidden layer with batch normalization
+	invStds     [][]float64  // 1 / √(σ² + ε) of each neuron of those layers
+	means       [][]float64
+	variances   [][]float64
+	masks       []mat.Matrix
+	deltas      []mat.Matrix // the errors `δ` of each layer, one row per input, filled by the backward pass
+}
+
+//--- METHODS
+
+// BackpropBatch returns the gradients of the total cost of the mini-batch with respect to the biases and weights,
+// and to the batch normalization parameters `γ` and `β` (which are nil if batch normalization is disabled).
+// The forward pass uses the statistics of the mini-batch, as during training, but doesn't update the running averages.
+func (net *Network2) BackpropBatch(batch Dataset) (biasesByLayer, weightsByLayer, gammasByLayer, betasByLayer []mat.Matrix) {
+	biasesByLayer, weightsByLayer, gammasByLayer, betasByLayer, _ = net.backpropBatch(batch)
+	return
+}
+
+// BatchLoss returns the sum of the costs of the inputs of the mini-batch, computed with the statistics of the mini-batch
+// but without dropout nor regularization.
+func (net *Network2) BatchLoss(batch Dataset) (c float64) {
+	cache := net.forwardBatch(batch, false)
+	output := cache.activations[len(cache.activations)-1]
+	for i, input := range batch {
+		c += net.Cost.Function(rowOf(output, i), input.Label.Vector)
+	}
+	return
+}
+
+// BatchNorm returns the batch normalization of each hidden layer, or nil if it's disabled.
+func (net *Network2) BatchNorm() []*BatchNorm {
+	return net.batchNorm
+}
+
+// BatchNormParameters returns the `γ` and `β` parameters of each hidden layer as 1×n matrices sharing their data with the network.
+func (net *Network2) BatchNormParameters() (gammas, betas []mat.Matrix) {
+	for _, bn := range net.batchNorm {
+		gammas = append(gammas, mat.NewDense(1, len(bn.Gamma), bn.Gamma))
+		betas = append(betas, mat.NewDense(1, len(bn.Beta), bn.Beta))
+	}
+	return
+}
+
+// SetBatchNorm enables or disables the batch normalization of the hidden layers.
+// Enabling it resets its parameters, ie. `γ = 1`, `β = 0` and running statistics of a standard distribution,
+// and switches the network back to float64 precision as batch normalization has no float32 implementation.
+func (net *Network2) SetBatchNorm(enabled bool) {
+	if !enabled {
+		net.batchNorm = nil
+		return
+	}
+	_ = net.SetPrecision(FLOAT64) // only switching to FLOAT32 can fail
+	net.batchNorm = make([]*BatchNorm, net.NumLayers()-2)
+	for i := range net.batchNorm {
+		size := net.Sizes[i+1]
+		bn := &BatchNorm{
+			Gamma:       make([]float64, size),
+			Beta:        make([]float64, size),
+			RunningMean: make([]float64, size),
+			RunningVar:  make([]float64, size),
+			Momentum:    DEFAULT_BATCH_NORM_MOMENTUM,
+			Epsilon:     DEFAULT_BATCH_NORM_EPSILON,
+		}
+		for j := 0; j < size; j++ {
+			bn.Gamma[j] = 1
+			bn.RunningVar[j] = 1
+		}
+		net.batchNorm[i] = bn
+	}
+}
+
+// normalize applies the batch normalization of the layer fed by `net.weights[i]` to the weighted input 'z' of a single input
+// using the running statistics, or returns 'z' untouched if the layer isn't normalized.
+func (net *Network2) normalize(i int, z mat.Matrix) mat.Matrix {
+	if i >= len(net.batchNorm) {
+		return z
+	}
+	bn := net.batchNorm[i]
+	return matrix.Apply(func(_, j int, v float64) float64 {
+		return bn.Gamma[j]*(v-bn.RunningMean[j])/math.Sqrt(bn.RunningVar[j]+bn.Epsilon) + bn.Beta[j]
+	}, z)
+}
+
+// forwardBatch feeds the whole mini-batch at once, each input being a row of the activation matrices.
+func (net *Network2) forwardBatch(batch Dataset, withDropout bool) (cache batchCache) {
+	m := len(batch)
+	x := mat.NewDense(m, net.Sizes[0], nil)
+	for i, input := range batch {
+		x.SetRow(i, input.Data)
+	}
+	cache.activations = []mat.Matrix{x}
+	cache.xHats = make([]mat.Matrix, net.NumLayers()-1)
+	cache.invStds = make([][]float64, net.NumLayers()-1)
+	cache.means = make([][]float64, net.NumLayers()-1)
+	cache.variances = make([][]float64, net.NumLayers()-1)
+	cache.masks = make([]mat.Matrix, net.NumLayers()-1)
+	a := mat.Matrix(x)
+	for i := 0; i < net.NumLayers()-1; i++ {
+		b := net.biases[i]
+		z := matrix.Apply(func(_, j int, v float64) float64 {
+			return v + b.At(0, j)
+		}, matrix.Dot(a, net.weights[i].T()))
+		y := z
+		if i < len(net.batchNorm) {
+			bn := net.batchNorm[i]
+			_, n := z.Dims()
+			mean, variance, invStd := make([]float64, n), make([]float64, n), make([]float64, n)
+			for j := 0; j < n; j++ {
+				for r := 0; r < m; r++ {
+					mean[j] += z.At(r, j) / float64(m)
+				}
+				for r := 0; r < m; r++ {
+					variance[j] += math.Pow(z.At(r, j)-mean[j], 2) / float64(m)
+				}
+				invStd[j] = 1 / math.Sqrt(variance[j]+bn.Epsilon)
+			}
+			xHat := matrix.Apply(func(_, j int, v float64) float64 {
+				return (v - mean[j]) * invStd[j]
+			}, z)
+			y = matrix.Apply(func(_, j int, v float64) float64 {
+				return bn.Gamma[j]*v + bn.Beta[j]
+			}, xHat)
+			cache.xHats[i], cache.invStds[i], cache.means[i], cache.variances[i] = xHat, invStd, mean, variance
+		}
+		cache.ys = append(cache.ys, y)
+		a = matrix.Apply(activation.Sigmoid, y)
+		if withDropout {
+			if mask := net.dropoutMask(i, y); mask != nil {
+				cache.masks[i] = mask
+				a = matrix.Multiply(a, mask)
+			}
+		}
+		cache.activations = append(cache.activations, a)
+	}
+	return
+}
+
+// backpropBatch returns the gradients summed over the mini-batch alongside the cache of the forward pass.
+func (net *Network2) backpropBatch(batch Dataset) (biasesByLayer, weightsByLayer, gammasByLayer, betasByLayer []mat.Matrix, cache batchCache) {
+	m := len(batch)
+	cache = net.forwardBatch(batch, true)
+	biasesByLayer = make([]mat.Matrix, net.NumLayers()-1)
+	weightsByLayer = make([]mat.Matrix, net.NumLayers()-1)
+	if net.batchNorm != nil {
+		gammasByLayer = make([]mat.Matrix, len(net.batchNorm))
+		betasByLayer = make([]mat.Matrix, len(net.batchNorm))
+	}
+
+	// Output error, one row per input
+	last := len(cache.ys) - 1
+	output := cache.activations[len(cache.activations)-1]
+	_, nOut := output.Dims()
+	delta := mat.NewDense(m, nOut, nil)
+	for r, input := range batch {
+		d := net.Cost.Delta(rowOf(output, r), input.Label.Vector, rowOf(cache.ys[last], r))
+		for j := 0; j < nOut; j++ {
+			delta.Set(r, j, d.At(0, j))
+		}
+	}
+
+	// Backward pass
+	cache.deltas = make([]mat.Matrix, len(cache.ys))
+	var dY mat.Matrix = delta
+	for i := last; i >= 0; i-- {
+		dZ := dY
+		if i < len(net.batchNorm) {
+			bn := net.batchNorm[i]
+			xHat := cache.xHats[i]
+			_, n := dY.Dims()
+			dGamma, dBeta := make([]float64, n), make([]float64, n)
+			sumDXHat, sumDXHatXHat := make([]float64, n), make([]float64, n)
+			for j := 0; j < n; j++ {
+				for r := 0; r < m; r++ {
+					dGamma[j] += dY.At(r, j) * xHat.At(r, j)
+					dBeta[j] += dY.At(r, j)
+					dXHat := dY.At(r, j) * bn.Gamma[j]
+					sumDXHat[j] += dXHat
+					sumDXHatXHat[j] += dXHat * xHat.At(r, j)
+				}
+			}
+			gammasByLayer[i] = mat.NewDense(1, n, dGamma)
+			betasByLayer[i] = mat.NewDense(1, n, dBeta)
+			invStd := cache.invStds[i]
+			dZ = matrix.Apply(func(r, j int, v float64) float64 {
+				dXHat := v * bn.Gamma[j]
+				return invStd[j] / float64(m) * (float64(m)*dXHat - sumDXHat[j] - xHat.At(r, j)*sumDXHatXHat[j])
+			}, dY)
+		}
+		cache.deltas[i] = dZ
+		_, n := dZ.Dims()
+		biasesByLayer[i] = mat.NewDense(1, n, mat.Col(nil, 0, matrix.Dot(dZ.T(), ones(m))))
+		weightsByLayer[i] = matrix.Dot(dZ.T(), cache.activations[i])
+		if i > 0 {
+			sp := matrix.Apply(activation.SigmoidPrime, cache.ys[i-1])
+			if mask := cache.masks[i-1]; mask != nil {
+				sp = matrix.Multiply(sp, mask)
+			}
+			dY = matrix.Multiply(matrix.Dot(dZ, net.weights[i]), sp)
+		}
+	}
+	return
+}
+
+// updateBatchNorm moves the parameters of the batch normalization layers against their gradients,
+// and updates the running statistics with those of the last mini-batch, unless it holds a single input whose variance
+// of 0 would drag the one used for inference towards 0, eg. the last mini-batch of an epoch.
+func (net *Network2) updateBatchNorm(gammasByLayer, betasByLayer []mat.Matrix, cache batchCache, eta float64, m int) {
+	for i, bn := range net.batchNorm {
+		for j := range bn.Gamma {
+			bn.Gamma[j] -= eta / float64(m) * gammasByLayer[i].At(0, j)
+			bn.Beta[j] -= eta / float64(m) * betasByLayer[i].At(0, j)
+			if m == 1 {
+				continue
+			}
+			unbiased := cache.variances[i][j] * float64(m) / float64(m-1)
+			bn.RunningMean[j] = bn.Momentum*bn.RunningMean[j] + (1-bn.Momentum)*cache.means[i][j]
+			bn.RunningVar[j] = bn.Momentum*bn.RunningVar[j] + (1-bn.Momentum)*unbiased
+		}
+	}
+}
+
+//--- FUNCTIONS
+
+func validateBatchNorm(batchNorm []*BatchNorm, sizes []int) error {
+	if len(batchNorm) != len(sizes)-2 {
+		return errors.New("batch normalization expected for each hidden layer")
+	}
+	for i, bn := range batchNorm {
+		size := sizes[i+1]
+		if len(bn.Gamma) != size || len(bn.Beta) != size || len(bn.RunningMean) != size || len(bn.RunningVar) != size {
+			return errors.New("invalid batch normalization size")
+		}
+	}
+	return nil
+}
+
+// utility functions
+
+func ones(n int) mat.Matrix {
+	data := make([]float64, n)
+	for i := range data {
+		data[i] = 1
+	}
+	return mat.NewDense(n, 1, data)
+}
+
+func rowOf(m mat.Matrix, i int) mat.Matrix {
+	_, c := m.Dims()
+	return mat.NewDense(1, c, mat.Row(nil, i, m))
+}
@@ -0,0 +1,30 @@
+package network_test
+
+import (
+	"neuraldeep/network"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestRunningStatistics checks that a mini-batch of a single input, whose variance is 0, leaves the running statistics alone.
+func TestRunningStatistics(t *testing.T) {
+	data := randomDataset(5, 3, 2)
+	net, err := network.Initial([]int{3, 4, 2})
+	assert.NilError(t, err)
+	net.SetBatchNorm(true)
+	bn := net.BatchNorm()[0]
+
+	net.UpdateMiniBatch(data[:1], 0.5, 0, len(data))
+	for j := range bn.RunningVar {
+		assert.Equal(t, bn.RunningMean[j], 0.)
+		assert.Equal(t, bn.RunningVar[j], 1.)
+	}
+	assert.Assert(t, bn.Beta[0] != 0) // the shift still learns
+
+	net.UpdateMiniBatch(data[1:3], 0.5, 0, len(data))
+	for j := range bn.RunningVar {
+		assert.Assert(t, bn.RunningMean[j] != 0)
+		assert.Assert(t, bn.RunningVar[j] != 1)
+	}
+}
@@ -0,0 +1,115 @@
+package network_test
+
+import (
+	"fmt"
+	"neuraldeep/network"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Run with `$ go test ./network -run=^$ -bench=. -benchmem`: each benchmark is declined for both implementations
+// and several layer configurations, its name ending with the sizes of the layers, eg. `BenchmarkBackprop/network2/784-100-10`.
+
+var benchSizes = [][]int{{784, 30, 10}, {784, 100, 10}, {784, 300, 100, 10}}
+
+// trainable is the part of both implementations the benchmarks go through.
+type trainable interface {
+	Backprop(x *network.Input) (biasesByLayer, weightsByLayer []mat.Matrix)
+	FeedForward(a mat.Vector) mat.Matrix
+}
+
+// BenchmarkFeedForward measures the inference of a single input.
+func BenchmarkFeedForward(b *testing.B) {
+	x := randomDataset(1, 784, 10)[0].ToVector()
+	eachNetwork(b, func(b *testing.B, net trainable) {
+		for i := 0; i < b.N; i++ {
+			net.FeedForward(x)
+		}
+	})
+}
+
+// BenchmarkBackprop measures the gradient of the cost of a single input.
+func BenchmarkBackprop(b *testing.B) {
+	x := randomDataset(1, 784, 10)[0]
+	eachNetwork(b, func(b *testing.B, net trainable) {
+		for i := 0; i < b.N; i++ {
+			net.Backprop(x)
+		}
+	})
+}
+
+// BenchmarkUpdateMiniBatch measures an epoch of 1,000 inputs in mini-batches of 10.
+func BenchmarkUpdateMiniBatch(b *testing.B) {
+	data := randomDataset(1000, 784, 10)
+	eachNetwork(b, func(b *testing.B, net trainable) {
+		for i := 0; i < b.N; i++ {
+			for k := 0; k+10 <= len(data); k += 10 {
+				switch n := net.(type) {
+				case *network.Network1:
+					n.UpdateMiniBatch(data[k:k+10], 3)
+				case *network.Network2:
+					n.UpdateMiniBatch(data[k:k+10], 0.5, 5, len(data))
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkTotalCost measures the regularized cost of the second implementation over 1,000 inputs.
+func BenchmarkTotalCost(b *testing.B) {
+	data := randomDataset(1000, 784, 10)
+	for _, sizes := range benchSizes {
+		net, _ := network.Initial(sizes)
+		b.Run(name(sizes), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				net.TotalCost(data, 5)
+			}
+		})
+	}
+}
+
+// BenchmarkLoadCSV measures the parsing of 1,000 MNIST-like lines, the format LoadData() reads.
+func BenchmarkLoadCSV(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "mnist.csv")
+	data := randomDataset(1000, 784, 10)
+	for _, input := range data {
+		for j, v := range input.Data {
+			input.Data[j] = float64(int(255 * v))
+		}
+	}
+	if err := network.SaveCSV(data, path); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := network.LoadCSV(path, 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// eachNetwork runs the benchmark for both implementations and all the layer configurations.
+func eachNetwork(b *testing.B, bench func(b *testing.B, net trainable)) {
+	for _, sizes := range benchSizes {
+		net1, _ := network.Init(sizes)
+		net2, _ := network.Initial(sizes)
+		for _, net := range []struct {
+			name string
+			trainable
+		}{{"network1", net1}, {"network2", net2}} {
+			b.Run(net.name+"/"+name(sizes), func(b *testing.B) {
+				b.ReportAllocs()
+				bench(b, net.trainable)
+			})
+		}
+	}
+}
+
+func name(sizes []int) string {
+	return strings.Trim(strings.Join(strings.Fields(fmt.Sprint(sizes)), "-"), "[]")
+}
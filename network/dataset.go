@@ -1,8 +1,11 @@
 package network
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
+	"neuraldeep/preprocess"
 	"neuraldeep/utils/python"
 	"time"
 
@@ -14,6 +17,12 @@ import (
 // Dataset ...
 type Dataset []*Input
 
+// Fold is one split of a k-fold cross-validation.
+type Fold struct {
+	Training   Dataset
+	Validation Dataset
+}
+
 // Input ...
 type Input struct {
 	Data  []float64
@@ -28,13 +37,87 @@ type Label struct {
 
 //--- METHODS
 
-// Shuffle ...
-func (ds Dataset) Shuffle() {
-	r := rand.New(rand.NewSource(time.Now().Unix()))
-	for i := len(ds) - 1; i > 0; i-- {
-		j := r.Intn(i + 1)
-		ds[i], ds[j] = ds[j], ds[i]
+// Fit fits the pipeline 'p' on the inputs of the dataset.
+func (ds Dataset) Fit(p preprocess.Pipeline) error {
+	raw := make([][]float64, len(ds))
+	for i, input := range ds {
+		raw[i] = input.Data
+	}
+	return p.Fit(raw)
+}
+
+// MiniBatches splits the dataset into consecutive mini-batches of 'size' inputs, the last one holding the remaining inputs
+// if 'size' doesn't divide the length of the dataset. The mini-batches share the inputs of the dataset.
+func (ds Dataset) MiniBatches(size int) []Dataset {
+	return miniBatches(ds, size)
+}
+
+// Shuffle randomly reorders the dataset in place.
+// If a random generator is passed, it is used instead of a time-seeded one so that the order is reproducible.
+func (ds Dataset) Shuffle(rng ...*rand.Rand) {
+	shuffle(ds, rng...)
+}
+
+// Transform returns a copy of the dataset whose inputs are transformed by the fitted pipeline 'p'.
+func (ds Dataset) Transform(p preprocess.Pipeline) Dataset {
+	transformed := make(Dataset, len(ds))
+	for i, input := range ds {
+		transformed[i] = &Input{
+			Data:  p.Transform(input.Data),
+			Label: input.Label,
+		}
+	}
+	return transformed
+}
+
+// StratifiedKFold splits the dataset into 'k' folds of roughly equal sizes, preserving the proportion of each label in every fold.
+// It returns the 'k' resulting splits, each fold being used once as the validation data while the others form the training data.
+// If a random generator is passed, it is used to shuffle the inputs of each class before distributing them.
+func (ds Dataset) StratifiedKFold(k int, rng ...*rand.Rand) (folds []Fold, err error) {
+	if k < 2 {
+		err = errors.New("at least two folds are needed")
+		return
+	}
+	if len(ds) < k {
+		err = fmt.Errorf("not enough data for %d folds", k)
+		return
+	}
+	var classes []int
+	byClass := make(map[int]Dataset)
+	for _, input := range ds {
+		if input.Label == nil {
+			err = errors.New("unlabelled input")
+			return
+		}
+		class := int(math.Round(input.Label.Value))
+		if _, exists := byClass[class]; !exists {
+			classes = append(classes, class)
+		}
+		byClass[class] = append(byClass[class], input)
 	}
+	parts := make([]Dataset, k)
+	i := 0
+	for _, class := range classes {
+		inputs := make(Dataset, len(byClass[class]))
+		copy(inputs, byClass[class])
+		inputs.Shuffle(rng...)
+		for _, input := range inputs {
+			parts[i%k] = append(parts[i%k], input)
+			i++
+		}
+	}
+	for f := range parts {
+		fold := Fold{
+			Validation: parts[f],
+		}
+		for g, part := range parts {
+			if g != f {
+				fold.Training = append(fold.Training, part...)
+			}
+		}
+		folds = append(folds, fold)
+	}
+	return
 }
 
 // ToVector ...
@@ -48,7 +131,7 @@ func (i *Input) ToVector() mat.Vector {
 // eg. if it's of size 10 then it means that the label could be any value from 0 to 9.
 func ToLabel(value float64, size int) *Label {
 	data := make([]float64, size)
-	for i := range python.XRange(0, size-1, 1) {
+	for i := range size {
 		if int(math.Round(value)) == i {
 			data[i] = 1.
 		} else {
@@ -60,3 +143,45 @@ func ToLabel(value float64, size int) *Label {
 		Vector: mat.NewVecDense(size, data),
 	}
 }
+
+// Synthetic returns 'n' inputs of 'size' values drawn uniformly in [0, 1), labelled in turn with each of the 'classes' classes,
+// eg. to measure the speed of a network without loading a real dataset.
+func Synthetic(n, size, classes int, rng *rand.Rand) (ds Dataset) {
+	ds = make(Dataset, n)
+	for i := range ds {
+		data := make([]float64, size)
+		for j := range data {
+			data[j] = rng.Float64()
+		}
+		ds[i] = &Input{Data: data, Label: ToLabel(float64(i%classes), classes)}
+	}
+	return
+}
+
+// miniBatches splits the list into consecutive mini-batches of 'size' items, the last one holding the remaining items.
+func miniBatches[S ~[]E, E any](s S, size int) []S {
+	if size <= 0 {
+		panic(fmt.Errorf("invalid mini-batch size: %d", size))
+	}
+	r := python.XRange(0, len(s), size)
+	batches := make([]S, r.Len())
+	for k := range batches {
+		start := r.At(k)
+		batches[k] = s[start:min(start+size, len(s))]
+	}
+	return batches
+}
+
+// shuffle randomly reorders the list in place, with the passed random generator if any.
+func shuffle[S ~[]E, E any](s S, rng ...*rand.Rand) {
+	var r *rand.Rand
+	if len(rng) > 0 && rng[0] != nil {
+		r = rng[0]
+	} else {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	for i := len(s) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,95 @@
+package network_test
+
+import (
+	"math/rand"
+	"neuraldeep/network"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestStratifiedKFold ...
+func TestStratifiedKFold(t *testing.T) {
+	var ds network.Dataset
+	for i := 0; i < 30; i++ {
+		label := 0.
+		if i >= 20 {
+			label = 1.
+		}
+		ds = append(ds, &network.Input{Data: []float64{float64(i)}, Label: network.ToLabel(label, 2)})
+	}
+	folds, err := ds.StratifiedKFold(5, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(folds), 5)
+	seen := make(map[float64]int)
+	for _, fold := range folds {
+		assert.Equal(t, len(fold.Validation), 6)
+		assert.Equal(t, len(fold.Training), 24)
+		ones := 0
+		for _, input := range fold.Validation {
+			seen[input.Data[0]]++
+			if input.Label.Value == 1 {
+				ones++
+			}
+		}
+		assert.Equal(t, ones, 2)
+	}
+	// Each input is used exactly once for validation
+	assert.Equal(t, len(seen), 30)
+	for _, count := range seen {
+		assert.Equal(t, count, 1)
+	}
+
+	_, err = ds.StratifiedKFold(1)
+	assert.Error(t, err, "at least two folds are needed")
+}
+
+// TestMiniBatches ...
+func TestMiniBatches(t *testing.T) {
+	ds := network.Synthetic(10, 2, 2, rand.New(rand.NewSource(1)))
+	for _, tt := range []struct {
+		size     int
+		expected []int
+	}{
+		{5, []int{5, 5}},
+		{3, []int{3, 3, 3, 1}},
+		{10, []int{10}},
+		{20, []int{10}},
+		{1, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+	} {
+		var lengths []int
+		var inputs network.Dataset
+		for _, miniBatch := range ds.MiniBatches(tt.size) {
+			lengths = append(lengths, len(miniBatch))
+			inputs = append(inputs, miniBatch...)
+		}
+		assert.DeepEqual(t, lengths, tt.expected)
+		// No input is dropped nor repeated
+		assert.Equal(t, len(inputs), len(ds))
+		for i := range ds {
+			assert.Equal(t, inputs[i], ds[i])
+		}
+	}
+	assert.Equal(t, len(network.Dataset{}.MiniBatches(10)), 0)
+}
+
+// TestMiniBatchesByLength ...
+func TestMiniBatchesByLength(t *testing.T) {
+	var ds network.SequenceDataset
+	for _, length := range []int{3, 5, 3, 0, 3, 5} {
+		ds = append(ds, make(network.Sequence, length))
+	}
+	var sizes, lengths []int
+	for _, miniBatch := range ds.MiniBatchesByLength(2) {
+		sizes = append(sizes, len(miniBatch))
+		for _, s := range miniBatch {
+			assert.Equal(t, len(s), len(miniBatch[0]))
+		}
+		lengths = append(lengths, len(miniBatch[0]))
+	}
+	// The empty sequence is left out
+	assert.DeepEqual(t, sizes, []int{2, 1, 2})
+	assert.DeepEqual(t, lengths, []int{3, 3, 5})
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// As in chapter 5 of the book, the speed at which a layer learns is measured through the magnitude of its error `δ = 𝛿C/𝛿b`
+// and of the changes applied to its weights: in a deep sigmoid stack, the earliest layers typically learn much slower than
+// the later ones (vanishing gradient problem), or much faster and unstably (exploding gradient problem).
+
+//--- TYPES
+
+// LayerGradients holds the learning speed of each layer over an epoch, the first item being the first hidden layer
+// and the last one the output layer: 'MeanDelta' is the mean of `|δ|` over all inputs and neurons of the layer,
+// and 'WeightUpdateNorm' is the mean over all mini-batches of the Frobenius norm of the update of the layer's weights.
+type LayerGradients struct {
+	MeanDelta        []float64 `json:"meanDelta"`
+	WeightUpdateNorm []float64 `json:"weightUpdateNorm"`
+}
+
+// gradientRecorder accumulates the learning speed of the layers during an epoch.
+type gradientRecorder struct {
+	sumDelta   []float64
+	nbDelta    []int
+	sumUpdate  []float64
+	nbUpdate   int
+	previousWs []mat.Matrix
+}
+
+//--- METHODS
+
+func (r *gradientRecorder) addDeltas(l int, deltas mat.Matrix) {
+	rows, cols := deltas.Dims()
+	for i := 0; i < rows; i++ {
+		sum := 0.
+		for j := 0; j < cols; j++ {
+			sum += math.Abs(deltas.At(i, j))
+		}
+		r.sumDelta[l] += sum / float64(cols)
+		r.nbDelta[l]++
+	}
+}
+
+func (r *gradientRecorder) before(weights []mat.Matrix) {
+	r.previousWs = make([]mat.Matrix, len(weights))
+	for l, w := range weights {
+		r.previousWs[l] = mat.DenseCopyOf(w)
+	}
+}
+
+func (r *gradientRecorder) after(weights []mat.Matrix) {
+	for l, w := range weights {
+		var update mat.Dense
+		update.Sub(w, r.previousWs[l])
+		r.sumUpdate[l] += mat.Norm(&update, 2)
+	}
+	r.nbUpdate++
+	r.previousWs = nil
+}
+
+func (r *gradientRecorder) result() (lg LayerGradients) {
+	for l := range r.sumDelta {
+		meanDelta, meanUpdate := 0., 0.
+		if r.nbDelta[l] > 0 {
+			meanDelta = r.sumDelta[l] / float64(r.nbDelta[l])
+		}
+		if r.nbUpdate > 0 {
+			meanUpdate = r.sumUpdate[l] / float64(r.nbUpdate)
+		}
+		lg.MeanDelta = append(lg.MeanDelta, meanDelta)
+		lg.WeightUpdateNorm = append(lg.WeightUpdateNorm, meanUpdate)
+	}
+	return
+}
+
+//--- FUNCTIONS
+
+// LearningSpeedTable returns a printable table of the learning speed of each layer, one line per epoch.
+func LearningSpeedTable(history []LayerGradients) string {
+	if len(history) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	nbLayers := len(history[0].MeanDelta)
+	sb.WriteString(fmt.Sprintf("%-7s", "epoch"))
+	for l := 0; l < nbLayers; l++ {
+		name := fmt.Sprintf("hidden #%d", l+1)
+		if l == nbLayers-1 {
+			name = "output"
+		}
+		sb.WriteString(fmt.Sprintf(" | %-25s", name+" mean|δ| / ‖Δw‖"))
+	}
+	sb.WriteString("\n")
+	for e, lg := range history {
+		sb.WriteString(fmt.Sprintf("%-7d", e+1))
+		for l := 0; l < nbLayers; l++ {
+			sb.WriteString(fmt.Sprintf(" | %-11.3e / %-11.3e", lg.MeanDelta[l], lg.WeightUpdateNorm[l]))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func newGradientRecorder(nbLayers int) *gradientRecorder {
+	return &gradientRecorder{
+		sumDelta:  make([]float64, nbLayers-1),
+		nbDelta:   make([]int, nbLayers-1),
+		sumUpdate: make([]float64, nbLayers-1),
+	}
+}
@@ -0,0 +1,56 @@
+package network_test
+
+import (
+	"math"
+	"neuraldeep/cost"
+	"neuraldeep/network"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestLearningSpeed compares the learning speed monitored by SGD() with the one computed by hand with Backprop().
+func TestLearningSpeed(t *testing.T) {
+	const eta = 0.5
+	data := randomDataset(6, 3, 2)
+	net, reference := twins(t, []int{3, 4, 2}, cost.CROSS_ENTROPY)
+
+	// A single mini-batch without regularization: the weights change by `-η/m ∑ ∂C/∂w`
+	meanDelta := make([]float64, 2)
+	sums := make([]*mat.Dense, 2)
+	for _, input := range data {
+		nablaB, nablaW := reference.Backprop(input)
+		for l := range nablaB {
+			_, c := nablaB[l].Dims()
+			for j := 0; j < c; j++ {
+				meanDelta[l] += math.Abs(nablaB[l].At(0, j)) / float64(c*len(data))
+			}
+			if sums[l] == nil {
+				sums[l] = mat.DenseCopyOf(nablaW[l])
+			} else {
+				sums[l].Add(sums[l], nablaW[l])
+			}
+		}
+	}
+	_, _, _, _, gradients := net.SGD(data, 1, len(data), eta, 0, nil, false, false, false, false, true)
+	assert.Equal(t, len(gradients), 1)
+	for l := range sums {
+		updateNorm := eta / float64(len(data)) * mat.Norm(sums[l], 2)
+		assert.Assert(t, math.Abs(gradients[0].MeanDelta[l]-meanDelta[l]) < 1e-12, "layer %d: %g != %g", l, gradients[0].MeanDelta[l], meanDelta[l])
+		assert.Assert(t, math.Abs(gradients[0].WeightUpdateNorm[l]-updateNorm) < 1e-12, "layer %d: %g != %g", l, gradients[0].WeightUpdateNorm[l], updateNorm)
+	}
+}
+
+// TestLearningSpeedTable ...
+func TestLearningSpeedTable(t *testing.T) {
+	assert.Equal(t, network.LearningSpeedTable(nil), "")
+	table := network.LearningSpeedTable([]network.LayerGradients{
+		{MeanDelta: []float64{0.0123, 0.25}, WeightUpdateNorm: []float64{0.5, 1.5}},
+		{MeanDelta: []float64{0.001, 0.125}, WeightUpdateNorm: []float64{0.25, 12}},
+	})
+	assert.Equal(t, table, ""+
+		"epoch   | hidden #1 mean|δ| / ‖Δw‖  | output mean|δ| / ‖Δw‖    \n"+
+		"1       | 1.230e-02   / 5.000e-01   | 2.500e-01   / 1.500e+00  \n"+
+		"2       | 1.000e-03   / 2.500e-01   | 1.250e-01   / 1.200e+01  \n")
+}
@@ -0,0 +1,341 @@
+package network
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"neuraldeep/cost"
+	"neuraldeep/regularization"
+	"neuraldeep/utils/tensor"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// In float32 precision, Network2 trains and predicts with single-precision tensors, which halves the memory used by the
+// parameters. The whole mini-batch is fed at once, each input being a row of the activation tensors, which are kept
+// in a workspace of their own from one mini-batch to the next. The float64 matrices returned by Parameters() are kept
+// in sync at the end of each epoch, and the saved file stores the float32 values only, about four times smaller.
+
+const (
+	FLOAT64 = "float64"
+	FLOAT32 = "float32"
+)
+
+//--- TYPES
+
+// workspace32 holds the tensors of the backpropagation of a whole mini-batch, layer by layer, each input being a row,
+// the gradients of the current mini-batch and the float64 copies of the weights the regularizer updates.
+type workspace32 struct {
+	input       *tensor.Tensor
+	target      *tensor.Tensor
+	zs          []*tensor.Tensor
+	activations []*tensor.Tensor
+	deltas      []*tensor.Tensor
+	nablaB      []*tensor.Tensor
+	nablaW      []*tensor.Tensor
+	penalties   []*mat.Dense
+}
+
+// Float32s is a list of float32 values encoded in JSON as the base64 string of their little-endian bytes.
+type Float32s []float32
+
+//--- METHODS
+
+// activation returns the activations of the layer of index 'l', the input being of index 0.
+func (ws *workspace32) activation(l int) *tensor.Tensor {
+	if l == 0 {
+		return ws.input
+	}
+	return ws.activations[l-1]
+}
+
+// fits tells whether the workspace was built for the passed layer sizes.
+func (ws *workspace32) fits(sizes []int) bool {
+	if len(ws.activations) != len(sizes)-1 || ws.input.Cols != sizes[0] {
+		return false
+	}
+	for l, a := range ws.activations {
+		if a.Cols != sizes[l+1] {
+			return false
+		}
+	}
+	return true
+}
+
+// resize sets the number of rows of the per-input tensors to the size 'm' of the mini-batch.
+func (ws *workspace32) resize(m int) {
+	ws.input.Resize(m)
+	ws.target.Resize(m)
+	for l := range ws.zs {
+		ws.zs[l].Resize(m)
+		ws.activations[l].Resize(m)
+		ws.deltas[l].Resize(m)
+	}
+}
+
+// MarshalJSON ...
+func (f Float32s) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, 4*len(f))
+	for i, v := range f {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(buf))
+}
+
+// UnmarshalJSON ...
+func (f *Float32s) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(buf)%4 != 0 {
+		return errors.New("invalid float32 data")
+	}
+	*f = make(Float32s, len(buf)/4)
+	for i := range *f {
+		(*f)[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return nil
+}
+
+// Precision returns the floating-point precision of the network's computations: FLOAT64 (the default) or FLOAT32.
+func (net *Network2) Precision() string {
+	if net.precision == "" {
+		return FLOAT64
+	}
+	return net.precision
+}
+
+// SetPrecision switches the computations of the network to FLOAT32 or back to FLOAT64.
+// Switching to float32 rounds the parameters to their nearest float32 values. It isn't available with dropout
+// or batch normalization, which only have a float64 implementation.
+func (net *Network2) SetPrecision(name string) error {
+	switch name {
+	case FLOAT64:
+		net.precision = FLOAT64
+		net.weights32, net.biases32 = nil, nil
+	case FLOAT32:
+		if net.dropout != nil {
+			return errors.New("dropout isn't available in float32 precision")
+		}
+		if net.batchNorm != nil {
+			return errors.New("batch normalization isn't available in float32 precision")
+		}
+		net.precision = FLOAT32
+		net.toFloat32()
+	default:
+		return fmt.Errorf("unavailable precision: %s", name)
+	}
+	return nil
+}
+
+// feedForward32 is the float32 counterpart of FeedForward(). It only reads the parameters, as it may be called concurrently.
+func (net *Network2) feedForward32(a mat.Vector) mat.Matrix {
+	output := tensor.FromMatrix(a.T())
+	for i, w := range net.weights32 {
+		z := tensor.Mul(output, false, w, true)
+		z.AddRow(net.biases32[i])
+		z.Apply(tensor.Sigmoid)
+		output = z
+	}
+	return output.Matrix()
+}
+
+// fromFloat32 refreshes the float64 parameters with the float32 ones, in place when they are already float64 copies.
+func (net *Network2) fromFloat32() {
+	refresh := func(params []mat.Matrix, tensors []*tensor.Tensor) {
+		for i, t := range tensors {
+			if d, ok := params[i].(*mat.Dense); ok && d.RawMatrix().Stride == t.Cols {
+				t.MatrixTo(d)
+			} else {
+				params[i] = t.Matrix()
+			}
+		}
+	}
+	refresh(net.weights, net.weights32)
+	refresh(net.biases, net.biases32)
+}
+
+// toFloat32 rounds the float64 parameters to float32 and keeps the results as the parameters of the float32 computations.
+func (net *Network2) toFloat32() {
+	net.weights32 = make([]*tensor.Tensor, len(net.weights))
+	for i, w := range net.weights {
+		net.weights32[i] = tensor.FromMatrix(w)
+	}
+	net.biases32 = make([]*tensor.Tensor, len(net.biases))
+	for i, b := range net.biases {
+		net.biases32[i] = tensor.FromMatrix(b)
+	}
+	net.fromFloat32()
+}
+
+// updateMiniBatch32 is the float32 counterpart of UpdateMiniBatch(), backpropagating the whole mini-batch at once
+// in the tensors of the network's float32 workspace.
+func (net *Network2) updateMiniBatch32(miniBatch Dataset, eta, lambda float64, n int) {
+	if net.recorder != nil {
+		net.recorder.before(matrices(net.weights32))
+		defer func() {
+			net.recorder.after(matrices(net.weights32))
+		}()
+	}
+	if net.buffers32 == nil || !net.buffers32.fits(net.Sizes) {
+		net.buffers32 = newWorkspace32(net.Sizes)
+	}
+	ws := net.buffers32
+	m := len(miniBatch)
+	ws.resize(m)
+	x, y := ws.input, ws.target
+	for i, input := range miniBatch {
+		for j, v := range input.Data {
+			x.Data[i*x.Cols+j] = float32(v)
+		}
+		for j := 0; j < y.Cols; j++ {
+			y.Data[i*y.Cols+j] = float32(input.Label.Vector.AtVec(j))
+		}
+	}
+	// Feedforward
+	for l, w := range net.weights32 {
+		tensor.MulTo(ws.zs[l], ws.activation(l), false, w, true)
+		ws.zs[l].AddRow(net.biases32[l])
+		ws.activations[l].Copy(ws.zs[l])
+		ws.activations[l].Apply(tensor.Sigmoid)
+	}
+	// Backward pass, each layer being updated once the error of the previous one is computed with its current weights
+	last := len(net.weights32) - 1
+	net.outputDelta32(ws, miniBatch)
+	k := float32(eta / float64(m))
+	for l := last; l >= 0; l-- {
+		delta := ws.deltas[l]
+		if net.recorder != nil {
+			net.recorder.addDeltas(l, delta.Matrix())
+		}
+		tensor.MulTo(ws.nablaW[l], delta, true, ws.activation(l), false)
+		delta.SumRowsTo(ws.nablaB[l])
+		if l > 0 {
+			tensor.MulTo(ws.deltas[l-1], delta, false, net.weights32[l], false)
+			mulSigmoidPrime32(ws.deltas[l-1], ws.activation(l))
+		}
+		if lambda != 0 {
+			penalize32(net.Regularizer, net.weights32[l], ws.penalties[l], eta, lambda, n)
+		}
+		net.weights32[l].AddScaled(-k, ws.nablaW[l])
+		net.biases32[l].AddScaled(-k, ws.nablaB[l])
+	}
+}
+
+// outputDelta32 writes the error of the output layer into the last delta of the workspace, from the activations and
+// weighted inputs of the mini-batch and their targets. Costs other than the quadratic and cross-entropy ones are
+// computed in float64, one input at a time.
+func (net *Network2) outputDelta32(ws *workspace32, miniBatch Dataset) {
+	last := len(ws.deltas) - 1
+	delta, a, z := ws.deltas[last], ws.activations[last], ws.zs[last]
+	switch net.Cost.GetName() {
+	case cost.CROSS_ENTROPY:
+		delta.Copy(a)
+		delta.Sub(ws.target)
+		return
+	case cost.QUADRATIC_COST:
+		delta.Copy(a)
+		delta.Sub(ws.target)
+		mulSigmoidPrime32(delta, a)
+		return
+	}
+	for i, input := range miniBatch {
+		row := func(t *tensor.Tensor) mat.Matrix {
+			return tensor.New(1, t.Cols, t.Data[i*t.Cols:(i+1)*t.Cols]).Matrix()
+		}
+		d := net.Cost.Delta(row(a), input.Label.Vector, row(z))
+		for j := 0; j < a.Cols; j++ {
+			delta.Data[i*a.Cols+j] = float32(d.At(0, j))
+		}
+	}
+}
+
+//--- FUNCTIONS
+
+// float64s converts the saved float32 parameters, checking them against the shapes of the network 'net'.
+func float64s(weights32, biases32 []Float32s, net *Network2) (weights, biases [][]float64, err error) {
+	if len(weights32) != len(net.weights) || len(biases32) != len(net.biases) {
+		return nil, nil, errors.New("invalid number of float32 layers")
+	}
+	convert := func(data Float32s, m mat.Matrix) ([]float64, error) {
+		r, c := m.Dims()
+		if len(data) != r*c {
+			return nil, fmt.Errorf("expected %d float32 values, got %d", r*c, len(data))
+		}
+		values := make([]float64, len(data))
+		for i, v := range data {
+			values[i] = float64(v)
+		}
+		return values, nil
+	}
+	for i := range weights32 {
+		w, err := convert(weights32[i], net.weights[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := convert(biases32[i], net.biases[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		weights, biases = append(weights, w), append(biases, b)
+	}
+	return
+}
+
+// newWorkspace32 allocates the tensors for a network of the passed layer sizes, resized to each mini-batch afterwards.
+func newWorkspace32(sizes []int) *workspace32 {
+	layers := len(sizes) - 1
+	ws := &workspace32{
+		input:       tensor.New(0, sizes[0], nil),
+		target:      tensor.New(0, sizes[layers], nil),
+		zs:          make([]*tensor.Tensor, layers),
+		activations: make([]*tensor.Tensor, layers),
+		deltas:      make([]*tensor.Tensor, layers),
+		nablaB:      make([]*tensor.Tensor, layers),
+		nablaW:      make([]*tensor.Tensor, layers),
+		penalties:   make([]*mat.Dense, layers),
+	}
+	for l := 0; l < layers; l++ {
+		in, out := sizes[l], sizes[l+1]
+		ws.zs[l] = tensor.New(0, out, nil)
+		ws.activations[l] = tensor.New(0, out, nil)
+		ws.deltas[l] = tensor.New(0, out, nil)
+		ws.nablaB[l] = tensor.New(1, out, nil)
+		ws.nablaW[l] = tensor.New(out, in, nil)
+		ws.penalties[l] = mat.NewDense(out, in, nil)
+	}
+	return ws
+}
+
+// matrices returns float64 copies of the tensors.
+func matrices(tensors []*tensor.Tensor) []mat.Matrix {
+	ms := make([]mat.Matrix, len(tensors))
+	for i, t := range tensors {
+		ms[i] = t.Matrix()
+	}
+	return ms
+}
+
+// penalize32 applies the weight penalty of the regularizer to the weights 'w' in place, going through the float64
+// matrix 'scratch' of the same dimensions since regularizers only handle float64 values.
+func penalize32(r regularization.Regularizer, w *tensor.Tensor, scratch *mat.Dense, eta, lambda float64, n int) {
+	w.MatrixTo(scratch)
+	r.UpdateTo(scratch, scratch, eta, lambda, n)
+	w.CopyMatrix(scratch)
+}
+
+// mulSigmoidPrime32 multiplies 'dst' element-wise by the derivative of the sigmoid function computed from its values 'a',
+// ie. `σ'(z) = σ(z)(1 - σ(z))`.
+func mulSigmoidPrime32(dst, a *tensor.Tensor) {
+	for i, v := range a.Data {
+		dst.Data[i] *= v * (1 - v)
+	}
+}
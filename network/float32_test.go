@@ -0,0 +1,122 @@
+package network_test
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/cost"
+	"neuraldeep/network"
+	"neuraldeep/regularization"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestFloat32 ...
+func TestFloat32(t *testing.T) {
+	data := randomDataset(50, 8, 3)
+	for _, name := range []string{cost.CROSS_ENTROPY, cost.QUADRATIC_COST} {
+		for _, reg := range []string{regularization.L2, regularization.L1, regularization.ELASTIC_NET} {
+			net64, net32 := twins(t, []int{8, 6, 5, 3}, name)
+			net64.Regularizer, _ = regularization.New(reg)
+			net32.Regularizer = net64.Regularizer
+			assert.NilError(t, net32.SetPrecision(network.FLOAT32))
+			assert.Equal(t, net32.Precision(), network.FLOAT32)
+
+			// The float32 backpropagation of the whole mini-batch matches the float64 one of each input
+			net64.UpdateMiniBatch(data[:10], 0.5, 2, len(data))
+			net32.UpdateMiniBatch(data[:10], 0.5, 2, len(data))
+			_, weights64 := net64.Parameters()
+			_, weights32 := net32.Parameters()
+			for l := range weights64 {
+				r, c := weights64[l].Dims()
+				for i := 0; i < r; i++ {
+					for j := 0; j < c; j++ {
+						assert.Assert(t, math.Abs(weights64[l].At(i, j)-weights32[l].At(i, j)) < 1e-5, "%s/%s: layer %d", name, reg, l)
+					}
+				}
+			}
+			output64, output32 := net64.FeedForward(data[0].ToVector()), net32.FeedForward(data[0].ToVector())
+			for j := 0; j < 3; j++ {
+				assert.Assert(t, math.Abs(output64.At(0, j)-output32.At(0, j)) < 1e-5)
+			}
+		}
+	}
+
+	// Training
+	net, err := network.Initial([]int{8, 6, 3})
+	assert.NilError(t, err)
+	assert.NilError(t, net.SetPrecision(network.FLOAT32))
+	before := net.TotalCost(data, 0)
+	net.SGD(data, 10, 5, 1, 0, nil)
+	assert.Assert(t, net.TotalCost(data, 0) < before)
+
+	// Saved float32 models are smaller and loaded back in float32 precision
+	dir := t.TempDir()
+	path32, path64 := filepath.Join(dir, "network32.json"), filepath.Join(dir, "network64.json")
+	assert.NilError(t, net.Save(path32))
+	loaded, err := network.Initial([]int{8, 6, 3})
+	assert.NilError(t, err)
+	assert.NilError(t, loaded.Load(path32))
+	assert.Equal(t, loaded.Precision(), network.FLOAT32)
+	for j := 0; j < 3; j++ {
+		assert.Equal(t, loaded.FeedForward(data[0].ToVector()).At(0, j), net.FeedForward(data[0].ToVector()).At(0, j))
+	}
+	assert.NilError(t, net.SetPrecision(network.FLOAT64))
+	assert.NilError(t, net.Save(path64))
+	info32, _ := os.Stat(path32)
+	info64, _ := os.Stat(path64)
+	assert.Assert(t, info32.Size() < info64.Size()/2)
+
+	assert.ErrorContains(t, net.SetPrecision("float16"), "unavailable")
+	net, _ = network.Initial([]int{8, 6, 3})
+	assert.NilError(t, net.SetDropout([]float64{0.5}))
+	assert.ErrorContains(t, net.SetPrecision(network.FLOAT32), "dropout")
+}
+
+// BenchmarkPrecision compares the training of a 784-30-10 network on 1,000 inputs and its inference in both precisions.
+func BenchmarkPrecision(b *testing.B) {
+	data := randomDataset(1000, 784, 10)
+	for _, precision := range []string{network.FLOAT64, network.FLOAT32} {
+		net, _ := network.Initial([]int{784, 30, 10})
+		if err := net.SetPrecision(precision); err != nil {
+			b.Fatal(err)
+		}
+		b.Run("train/"+precision, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for k := 0; k+10 <= len(data); k += 10 {
+					net.UpdateMiniBatch(data[k:k+10], 0.5, 5, len(data))
+				}
+			}
+		})
+		b.Run("predict/"+precision, func(b *testing.B) {
+			x := data[0].ToVector()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				net.FeedForward(x)
+			}
+		})
+	}
+}
+
+// randomDataset returns 'n' reproducible inputs of 'size' values in [0, 1) labelled with one of the 'classes' classes.
+func randomDataset(n, size, classes int) network.Dataset {
+	return network.Synthetic(n, size, classes, rand.New(rand.NewSource(1)))
+}
+
+// twins returns two networks with the same parameters.
+func twins(t *testing.T, sizes []int, costName string) (*network.Network2, *network.Network2) {
+	c, err := cost.New(costName)
+	assert.NilError(t, err)
+	a, err := network.Initial(sizes, c)
+	assert.NilError(t, err)
+	path := filepath.Join(t.TempDir(), "twin.json")
+	assert.NilError(t, a.Save(path))
+	b, err := network.Initial(sizes, c)
+	assert.NilError(t, err)
+	assert.NilError(t, b.Load(path))
+	assert.NilError(t, a.Load(path))
+	return a, b
+}
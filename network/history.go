@@ -0,0 +1,103 @@
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// The monitoring of a training can be saved as a JSONL log, one JSON object per line and per epoch, eg.
+// `{"epoch":1,"trainingCost":0.52,"trainingAccuracy":0.91,"evaluationCost":0.55,"evaluationAccuracy":0.90}`.
+// The accuracies are stored as the fraction of correct results so that datasets of different sizes can be compared.
+
+//--- TYPES
+
+// Epoch is the record of one epoch of training, each field being nil if the corresponding value wasn't monitored.
+// 'Duration' is the number of seconds the gradient descent of the epoch took, monitoring excluded, 'Samples' the number
+// of inputs it went through and 'Eta' its learning rate, when known.
+type Epoch struct {
+	Epoch              int      `json:"epoch"`
+	TrainingCost       *float64 `json:"trainingCost,omitempty"`
+	TrainingAccuracy   *float64 `json:"trainingAccuracy,omitempty"`
+	EvaluationCost     *float64 `json:"evaluationCost,omitempty"`
+	EvaluationAccuracy *float64 `json:"evaluationAccuracy,omitempty"`
+	Duration           float64  `json:"duration,omitempty"`
+	Samples            int      `json:"samples,omitempty"`
+	Eta                float64  `json:"eta,omitempty"`
+}
+
+//--- FUNCTIONS
+
+// History builds the records of each epoch out of the lists returned by Network2.SGD(), where 'nTraining' and 'nEvaluation'
+// are the sizes of the training and evaluation datasets used to turn the accuracies into fractions.
+func History(evaluationCost []float64, evaluationAccuracy []int, trainingCost []float64, trainingAccuracy []int, nTraining, nEvaluation int) (history []Epoch) {
+	epochs := max(len(evaluationCost), len(evaluationAccuracy), len(trainingCost), len(trainingAccuracy))
+	for j := 0; j < epochs; j++ {
+		history = append(history, epochAt(j, evaluationCost, evaluationAccuracy, trainingCost, trainingAccuracy, nTraining, nEvaluation))
+	}
+	return
+}
+
+// LoadHistory reads the JSONL training log at 'path'.
+func LoadHistory(path string) (history []Epoch, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Epoch
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return
+		}
+		history = append(history, e)
+	}
+	err = scanner.Err()
+	return
+}
+
+// SaveHistory writes the records to a JSONL training log at 'path', creating its folder if need be.
+func SaveHistory(history []Epoch, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range history {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// epochAt returns the record of the epoch of index 'j' in the monitored lists, leaving out the values they lack.
+func epochAt(j int, evaluationCost []float64, evaluationAccuracy []int, trainingCost []float64, trainingAccuracy []int, nTraining, nEvaluation int) Epoch {
+	e := Epoch{Epoch: j + 1}
+	if j < len(trainingCost) {
+		cost := trainingCost[j]
+		e.TrainingCost = &cost
+	}
+	if j < len(trainingAccuracy) && nTraining > 0 {
+		accuracy := float64(trainingAccuracy[j]) / float64(nTraining)
+		e.TrainingAccuracy = &accuracy
+	}
+	if j < len(evaluationCost) {
+		cost := evaluationCost[j]
+		e.EvaluationCost = &cost
+	}
+	if j < len(evaluationAccuracy) && nEvaluation > 0 {
+		accuracy := float64(evaluationAccuracy[j]) / float64(nEvaluation)
+		e.EvaluationAccuracy = &accuracy
+	}
+	return e
+}
@@ -0,0 +1,30 @@
+package network_test
+
+import (
+	"neuraldeep/network"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestHistory ...
+func TestHistory(t *testing.T) {
+	history := network.History(nil, []int{80, 90}, []float64{0.5, 0.3}, []int{400, 450}, 500, 100)
+	assert.Equal(t, len(history), 2)
+	assert.Equal(t, history[1].Epoch, 2)
+	assert.Equal(t, *history[1].TrainingCost, 0.3)
+	assert.Equal(t, *history[1].TrainingAccuracy, 0.9)
+	assert.Equal(t, *history[0].EvaluationAccuracy, 0.8)
+	assert.Assert(t, history[0].EvaluationCost == nil)
+
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := network.SaveHistory(history, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := network.LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, loaded, history)
+}
@@ -0,0 +1,120 @@
+package network
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"neuraldeep/utils/matrix"
+	"neuraldeep/utils/python"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	DEFAULT_INITIALIZER    = "default"
+	LARGE_INITIALIZER      = "large"
+	XAVIER_INITIALIZER     = "xavier"
+	HE_INITIALIZER         = "he"
+	LECUN_INITIALIZER      = "lecun"
+	ORTHOGONAL_INITIALIZER = "orthogonal"
+)
+
+//--- TYPES
+
+// Initializer returns the initial biases and weights of a network whose layers have the passed sizes.
+// Note that the first layer is assumed to be an input layer, and by convention we won't set any biases
+// for those neurons, since biases are only ever used in computing the outputs from later layers.
+// If a random generator is passed, the values are drawn from it instead of the global one, eg. to reproduce them.
+type Initializer func(sizes []int, rng ...*rand.Rand) (biases, weights []mat.Matrix, err error)
+
+//--- FUNCTIONS
+
+// NewInitializer returns the weight initializer registered under the passed name.
+func NewInitializer(name string) (Initializer, error) {
+	switch name {
+	case DEFAULT_INITIALIZER:
+		return DefaultWeightInitializer, nil
+	case LARGE_INITIALIZER:
+		return LargeWeightInitializer, nil
+	case XAVIER_INITIALIZER:
+		return XavierWeightInitializer, nil
+	case HE_INITIALIZER:
+		return HeWeightInitializer, nil
+	case LECUN_INITIALIZER:
+		return LeCunWeightInitializer, nil
+	case ORTHOGONAL_INITIALIZER:
+		return OrthogonalWeightInitializer, nil
+	default:
+		return nil, errors.New("unavailable weight initializer")
+	}
+}
+
+// XavierWeightInitializer (aka. Glorot) draws each weight from a Gaussian distribution with mean 0 and standard deviation
+// `√(2 / (n_in + n_out))`, keeping the variance of the activations and of the gradients roughly constant across layers.
+// The biases are set to 0.
+func XavierWeightInitializer(sizes []int, rng ...*rand.Rand) (biases, weights []mat.Matrix, err error) {
+	return scaledGaussianInitializer(sizes, rng, func(nIn, nOut int) float64 {
+		return math.Sqrt(2 / float64(nIn+nOut))
+	})
+}
+
+// HeWeightInitializer (aka. Kaiming) draws each weight from a Gaussian distribution with mean 0 and standard deviation
+// `√(2 / n_in)`, which suits rectifier activations. The biases are set to 0.
+func HeWeightInitializer(sizes []int, rng ...*rand.Rand) (biases, weights []mat.Matrix, err error) {
+	return scaledGaussianInitializer(sizes, rng, func(nIn, _ int) float64 {
+		return math.Sqrt(2 / float64(nIn))
+	})
+}
+
+// LeCunWeightInitializer draws each weight from a Gaussian distribution with mean 0 and standard deviation `√(1 / n_in)`.
+// Unlike DefaultWeightInitializer(), the biases are set to 0.
+func LeCunWeightInitializer(sizes []int, rng ...*rand.Rand) (biases, weights []mat.Matrix, err error) {
+	return scaledGaussianInitializer(sizes, rng, func(nIn, _ int) float64 {
+		return math.Sqrt(1 / float64(nIn))
+	})
+}
+
+// OrthogonalWeightInitializer sets each weight matrix to a random (semi-)orthogonal matrix and the biases to 0.
+func OrthogonalWeightInitializer(sizes []int, rng ...*rand.Rand) (biases, weights []mat.Matrix, err error) {
+	if len(sizes) < 2 {
+		err = errors.New("not enough layers")
+		return
+	}
+	tuples, err := python.Zip(sizes[:len(sizes)-1], sizes[1:])
+	if err != nil {
+		return
+	}
+	biases = zeroBiases(sizes)
+	weights = make([]mat.Matrix, len(tuples))
+	for i, tuple := range tuples {
+		weights[i] = matrix.Orthogonal(tuple.J, tuple.I, 1., rng...)
+	}
+	return
+}
+
+// utility functions
+
+func scaledGaussianInitializer(sizes []int, rng []*rand.Rand, stdDev func(nIn, nOut int) float64) (biases, weights []mat.Matrix, err error) {
+	if len(sizes) < 2 {
+		err = errors.New("not enough layers")
+		return
+	}
+	tuples, err := python.Zip(sizes[:len(sizes)-1], sizes[1:])
+	if err != nil {
+		return
+	}
+	biases = zeroBiases(sizes)
+	weights = make([]mat.Matrix, len(tuples))
+	for i, tuple := range tuples {
+		weights[i] = matrix.Gaussian(tuple.J, tuple.I, 0, stdDev(tuple.I, tuple.J), rng...)
+	}
+	return
+}
+
+func zeroBiases(sizes []int) []mat.Matrix {
+	bs := make([]mat.Matrix, len(sizes)-1)
+	for i, size := range sizes[1:] {
+		bs[i] = mat.NewDense(1, size, nil)
+	}
+	return bs
+}
@@ -0,0 +1,58 @@
+package network_test
+
+import (
+	"math/rand"
+	"neuraldeep/network"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestInitializer checks that each initializer draws the same biases and weights from the same seed.
+func TestInitializer(t *testing.T) {
+	sizes := []int{5, 4, 3}
+	for _, name := range []string{
+		network.DEFAULT_INITIALIZER, network.LARGE_INITIALIZER, network.XAVIER_INITIALIZER,
+		network.HE_INITIALIZER, network.LECUN_INITIALIZER, network.ORTHOGONAL_INITIALIZER,
+	} {
+		initializer, err := network.NewInitializer(name)
+		assert.NilError(t, err)
+		biases, weights, err := initializer(sizes, rand.New(rand.NewSource(7)))
+		assert.NilError(t, err)
+		sameBiases, sameWeights, err := initializer(sizes, rand.New(rand.NewSource(7)))
+		assert.NilError(t, err)
+		_, otherWeights, err := initializer(sizes, rand.New(rand.NewSource(8)))
+		assert.NilError(t, err)
+		for l := range weights {
+			assert.Assert(t, mat.Equal(biases[l], sameBiases[l]), name)
+			assert.Assert(t, mat.Equal(weights[l], sameWeights[l]), name)
+			assert.Assert(t, !mat.Equal(weights[l], otherWeights[l]), name)
+		}
+	}
+
+	_, err := network.NewInitializer("unknown")
+	assert.Error(t, err, "unavailable weight initializer")
+}
+
+// TestInitialWith ...
+func TestInitialWith(t *testing.T) {
+	sizes := []int{5, 4, 3}
+	a, err := network.InitialWith(sizes, network.XAVIER_INITIALIZER, rand.New(rand.NewSource(7)))
+	assert.NilError(t, err)
+	assert.Equal(t, a.Initializer(), network.XAVIER_INITIALIZER)
+
+	// The network holds the very weights the initializer draws from the seed
+	initializer, _ := network.NewInitializer(network.XAVIER_INITIALIZER)
+	biases, weights, _ := initializer(sizes, rand.New(rand.NewSource(7)))
+	actualBiases, actualWeights := a.Parameters()
+	for l := range weights {
+		assert.Assert(t, mat.Equal(actualBiases[l], biases[l]))
+		assert.Assert(t, mat.Equal(actualWeights[l], weights[l]))
+	}
+
+	_, err = network.InitialWith(sizes, "unknown", nil)
+	assert.Error(t, err, "unavailable weight initializer")
+	_, err = network.InitialWith([]int{5}, network.XAVIER_INITIALIZER, nil)
+	assert.Error(t, err, "not enough layers")
+}
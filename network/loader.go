@@ -3,7 +3,9 @@ package network
 import (
 	"bufio"
 	"encoding/csv"
+	"fmt"
 	"io"
+	"math"
 	"neuraldeep/utils"
 	"os"
 	"strconv"
@@ -37,6 +39,10 @@ func LoadData() (training Dataset, validation Dataset, test Dataset, err error)
 		if e == io.EOF {
 			break
 		}
+		if len(record) != sizeLine {
+			err = fmt.Errorf("invalid MNIST line of size %d", len(record))
+			return
+		}
 		input, e := readLine(record, 10)
 		if e != nil {
 			err = e
@@ -70,6 +76,10 @@ func LoadData() (training Dataset, validation Dataset, test Dataset, err error)
 		if e == io.EOF {
 			break
 		}
+		if len(record) != sizeLine {
+			err = fmt.Errorf("invalid MNIST line of size %d", len(record))
+			return
+		}
 		input, e := readLine(record, 10)
 		if e != nil {
 			err = e
@@ -81,9 +91,74 @@ func LoadData() (training Dataset, validation Dataset, test Dataset, err error)
 	return
 }
 
+// LoadCSV reads a whole dataset from the CSV file at 'path' using the same format as the MNIST sets,
+// ie. each line starts with the label followed by the input data, 'size' being the size of the output layer.
+// If passed, 'inputSize' is the size of the input layer, which every line must match. Each label must be a whole number in `[0, size)`.
+func LoadCSV(path string, size int, inputSize ...int) (dataset Dataset, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	r := csv.NewReader(bufio.NewReader(f))
+	line := 0
+	for {
+		record, e := r.Read()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			err = e
+			return
+		}
+		line++
+		if len(record) < 2 {
+			err = fmt.Errorf("line %d: a label and at least one value are expected", line)
+			return
+		}
+		if len(inputSize) > 0 && len(record)-1 != inputSize[0] {
+			err = fmt.Errorf("line %d: %d values for an input layer of %d neurons", line, len(record)-1, inputSize[0])
+			return
+		}
+		input, e := readLine(record, size)
+		if e != nil {
+			err = fmt.Errorf("line %d: %w", line, e)
+			return
+		}
+		dataset = append(dataset, &input)
+	}
+	return
+}
+
+// SaveCSV writes the dataset to the file at 'path' in the format read by LoadCSV(),
+// ie. one line per input starting with the value of its label followed by its data.
+func SaveCSV(ds Dataset, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	for i, input := range ds {
+		if input.Label == nil {
+			return fmt.Errorf("input %d: missing label", i)
+		}
+		record := make([]string, len(input.Data)+1)
+		record[0] = strconv.FormatFloat(input.Label.Value, 'g', -1, 64)
+		for j, d := range input.Data {
+			record[j+1] = strconv.FormatFloat(d, 'g', -1, 64)
+		}
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
 func readLine(record []string, size int) (input Input, err error) {
-	data := make([]float64, sizeLine)
-	for i := 0; i < sizeLine; i++ {
+	data := make([]float64, len(record))
+	for i := 0; i < len(record); i++ {
 		d, e := strconv.ParseFloat(record[i], 64)
 		if e != nil {
 			err = e
@@ -91,9 +166,18 @@ func readLine(record []string, size int) (input Input, err error) {
 		}
 		data[i] = d
 	}
+	label := data[0]
+	if label != math.Trunc(label) {
+		err = fmt.Errorf("label %g isn't a whole number", label)
+		return
+	}
+	if label < 0 || label >= float64(size) {
+		err = fmt.Errorf("label %g outside [0, %d)", label, size)
+		return
+	}
 	input = Input{
 		Data:  data[1:],
-		Label: ToLabel(data[0], size),
+		Label: ToLabel(label, size),
 	}
 	return
 }
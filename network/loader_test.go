@@ -0,0 +1,40 @@
+package network_test
+
+import (
+	"math/rand"
+	"neuraldeep/network"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestLoadCSV ...
+func TestLoadCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	data := network.Synthetic(5, 4, 3, rand.New(rand.NewSource(1)))
+	if err := network.SaveCSV(data, path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := network.LoadCSV(path, 3, 4)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded), 5)
+	assert.DeepEqual(t, loaded[0].Data, data[0].Data)
+	assert.Equal(t, loaded[0].Label.Value, data[0].Label.Value)
+
+	_, err = network.LoadCSV(path, 3, 784)
+	assert.Error(t, err, "line 1: 4 values for an input layer of 784 neurons")
+
+	// The labels must be classes of the output layer
+	for content, expected := range map[string]string{
+		"0,0.5\n3,0.5\n": "line 2: label 3 outside [0, 3)",
+		"-1,0.5\n":       "line 1: label -1 outside [0, 3)",
+		"1.5,0.5\n":      "line 1: label 1.5 isn't a whole number",
+	} {
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0644))
+		_, err = network.LoadCSV(path, 3)
+		assert.Error(t, err, expected)
+	}
+}
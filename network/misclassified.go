@@ -0,0 +1,56 @@
+package network
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+//--- TYPES
+
+// Misclassification is an input of a dataset for which the network didn't output the correct result.
+type Misclassification struct {
+	Index     int
+	Input     *Input
+	Predicted int
+}
+
+//--- METHODS
+
+// Misclassified returns the inputs in 'data' for which the neural network doesn't output the correct result, in the dataset order.
+func (net *Network1) Misclassified(data Dataset) []Misclassification {
+	return misclassified(data, net.FeedForward)
+}
+
+// Misclassified returns the inputs in 'data' for which the neural network doesn't output the correct result, in the dataset order.
+// As with Accuracy(), the inputs are expected to be already preprocessed.
+func (net *Network2) Misclassified(data Dataset) []Misclassification {
+	return misclassified(data, net.FeedForward)
+}
+
+//--- FUNCTIONS
+
+// Argmax returns the index of the neuron with the highest activation in the passed output row.
+func Argmax(output mat.Matrix) (index int) {
+	_, c := output.Dims()
+	for i := 1; i < c; i++ {
+		if output.At(0, i) > output.At(0, index) {
+			index = i
+		}
+	}
+	return
+}
+
+func misclassified(data Dataset, feedForward func(a mat.Vector) mat.Matrix) (errs []Misclassification) {
+	for i, input := range data {
+		predicted := Argmax(feedForward(input.ToVector()))
+		if predicted != int(math.Round(input.Label.Value)) {
+			errs = append(errs, Misclassification{
+				Index:     i,
+				Input:     input,
+				Predicted: predicted,
+			})
+		}
+	}
+	return
+}
@@ -1,9 +1,21 @@
 package network
 
+import (
+	"neuraldeep/preprocess"
+)
+
 // Network is the JSON representation of the Networks.
 type Network struct {
-	Sizes   []int       `json:"sizes"`
-	Cost    string      `json:"cost,omitempty"`
-	Weights [][]float64 `json:"weights"`
-	Biases  [][]float64 `json:"biases"`
+	Sizes         []int               `json:"sizes"`
+	Cost          string              `json:"cost,omitempty"`
+	Regularizer   string              `json:"regularizer,omitempty"`
+	Precision     string              `json:"precision,omitempty"`
+	Weights       [][]float64         `json:"weights,omitempty"`
+	Biases        [][]float64         `json:"biases,omitempty"`
+	Weights32     []Float32s          `json:"weights32,omitempty"`
+	Biases32      []Float32s          `json:"biases32,omitempty"`
+	Dropout       []float64           `json:"dropout,omitempty"`
+	Initializer   string              `json:"initializer,omitempty"`
+	Preprocessing preprocess.Pipeline `json:"preprocessing,omitempty"`
+	BatchNorm     []*BatchNorm        `json:"batchNorm,omitempty"`
 }
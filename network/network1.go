@@ -25,6 +25,7 @@ type Network1 struct {
 	numLayers int
 	weights   []mat.Matrix
 	biases    []mat.Matrix
+	buffers   *workspace
 }
 
 //--- METHODS
@@ -59,9 +60,9 @@ func (net *Network1) Backprop(x *Input) (biasesByLayer, weightsByLayer []mat.Mat
 	biasesByLayer[len(biasesByLayer)-1] = delta
 	weightsByLayer[len(weightsByLayer)-1] = matrix.Dot(delta.T(), activations[len(activations)-2])
 	if net.NumLayers() > 2 {
-		for l := range python.XRange(2, net.NumLayers()-1, 1) {
+		for l := 2; l < net.NumLayers(); l++ {
 			z := zs[len(zs)-l]
-			sp := matrix.Apply(activation.Sigmoid, z)
+			sp := matrix.Apply(activation.SigmoidPrime, z)
 			delta = matrix.Multiply(matrix.Dot(delta, net.weights[len(net.weights)-l+1]), sp)
 			biasesByLayer[len(biasesByLayer)-l] = delta
 			weightsByLayer[len(weightsByLayer)-l] = matrix.Dot(delta.T(), activations[len(activations)-l-1])
@@ -117,22 +118,13 @@ func (net *Network1) FeedForward(a mat.Vector) (output mat.Matrix) {
 // If 'test' dataset is provided then the network will be evaluated against the test data after each epoch,
 // and partial progress printed out. This is useful for tracking progress, but slows things down substantially.
 func (net *Network1) SGD(training Dataset, epochs int, miniBatchSize int, eta float64, test ...Dataset) {
-	var (
-		nTest int
-		n     int
-	)
+	var nTest int
 	if len(test) > 0 {
 		nTest = len(test[0])
 	}
-	n = len(training)
 	for j := 0; j < epochs; j++ {
 		training.Shuffle()
-		var miniBatches []Dataset
-		for k := range python.XRange(0, n-miniBatchSize, miniBatchSize) {
-			miniBatch := training[k : k+miniBatchSize]
-			miniBatches = append(miniBatches, miniBatch)
-		}
-		for _, miniBatch := range miniBatches {
+		for _, miniBatch := range training.MiniBatches(miniBatchSize) {
 			net.UpdateMiniBatch(miniBatch, eta)
 		}
 		if len(test) > 0 {
@@ -146,40 +138,36 @@ func (net *Network1) SGD(training Dataset, epochs int, miniBatchSize int, eta fl
 // UpdateMiniBatch updates the network's weights and biases by applying gradient descent
 // using backpropagation to a single mini batch.
 // The 'miniBatch' is a list of `Inputs`, and 'eta' is the learning rate.
+// The gradients are computed in the buffers of the network's workspace and the parameters are updated in place.
 func (net *Network1) UpdateMiniBatch(miniBatch Dataset, eta float64) {
-	var biasesByLayer []mat.Matrix
-	for _, b := range net.biases {
-		r, c := b.Dims()
-		data := make([]float64, r*c)
-		biasesLayer := mat.NewDense(r, c, data)
-		biasesByLayer = append(biasesByLayer, biasesLayer)
-	}
-	var weightsByLayer []mat.Matrix
-	for _, w := range net.weights {
-		r, c := w.Dims()
-		data := make([]float64, r*c)
-		weightsLayer := mat.NewDense(r, c, data)
-		weightsByLayer = append(weightsByLayer, weightsLayer)
+	if net.buffers == nil || !net.buffers.fits(net.Sizes) {
+		net.buffers = newWorkspace(net.Sizes)
 	}
+	ws := net.buffers
+	ws.reset()
+	last := len(net.weights) - 1
 	for _, input := range miniBatch {
-		deltaBiasesByLayer, deltaWeightsByLayer := net.Backprop(input)
-		for i, biases := range biasesByLayer {
-			biasesByLayer[i] = matrix.Add(biases, deltaBiasesByLayer[i])
-		}
-		for i, weights := range weightsByLayer {
-			weightsByLayer[i] = matrix.Add(weights, deltaWeightsByLayer[i])
+		ws.forward(net.weights, net.biases, input, nil)
+		// δ = (a - y) ⊙ σ'(z)
+		a, z, delta := ws.activations[last], ws.zs[last], ws.deltas[last]
+		_, c := a.Dims()
+		for j := 0; j < c; j++ {
+			delta.Set(0, j, (a.At(0, j)-input.Label.Vector.AtVec(j))*activation.SigmoidPrime(0, j, z.At(0, j)))
 		}
+		ws.backward(net.weights)
 	}
-	for i, biases := range net.biases {
-		net.biases[i] = matrix.Subtract(biases, matrix.Scale(eta/float64(len(miniBatch)), biasesByLayer[i]))
-	}
-	for i, weights := range net.weights {
-		net.weights[i] = matrix.Subtract(weights, matrix.Scale(eta/float64(len(miniBatch)), weightsByLayer[i]))
-	}
+	k := eta / float64(len(miniBatch))
+	descend(net.biases, ws.sumB, k)
+	descend(net.weights, ws.sumW, k)
 }
 
 //---
 
+// Loss returns the quadratic cost `C_x = ½‖a - y‖²` whose derivative is given by CostDerivative().
+func (net *Network1) Loss(x *Input) float64 {
+	return 0.5 * math.Pow(mat.Norm(net.CostDerivative(net.FeedForward(x.ToVector()), x.Label.Vector), 2), 2)
+}
+
 // NumLayers is utility method returning the number of layers in the network.
 func (net *Network1) NumLayers() int {
 	return net.numLayers
@@ -190,6 +178,12 @@ func (net *Network1) OutputSize() int {
 	return net.Sizes[net.NumLayers()-1]
 }
 
+// Parameters returns the biases and weights of the network layer by layer.
+// Beware that these are the actual matrices used by the network and not copies.
+func (net *Network1) Parameters() (biases, weights []mat.Matrix) {
+	return net.biases, net.weights
+}
+
 // Save records the network to a './data/saved/network/' folder
 func (net *Network1) Save() error {
 	for i, biases := range net.biases {
@@ -239,7 +233,7 @@ func Init(sizes []int) (n *Network1, err error) {
 	// Biases
 	biases := make([]mat.Matrix, len(sizes)-1)
 	for i, size := range sizes[1:] {
-		biases[i] = matrix.Random(1, size, 2.)
+		biases[i] = matrix.Gaussian(1, size, 0, 1)
 	}
 
 	// Weights
@@ -249,7 +243,7 @@ func Init(sizes []int) (n *Network1, err error) {
 	}
 	weights := make([]mat.Matrix, len(tuples))
 	for i, tuple := range tuples {
-		weights[i] = matrix.Random(tuple.J, tuple.I, 2.)
+		weights[i] = matrix.Gaussian(tuple.J, tuple.I, 0, 1)
 	}
 
 	return &Network1{
@@ -273,11 +267,14 @@ func Load(to *Network1, path string) error {
 		w := mat.DenseCopyOf(to.weights[i])
 		w.Reset()
 		if _, err = w.UnmarshalBinaryFrom(f); err != nil {
-			panic(err)
+			return err
 		}
 		to.weights[i] = w
 		i++
 	}
+	if i == 0 {
+		return errors.New("no saved layer found in " + path)
+	}
 	for j := 0; j < i; j++ {
 		filepath := fmt.Sprintf(path+"biases%d.layer", j)
 		if f, err := os.Open(filepath); err == nil {
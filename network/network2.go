@@ -6,24 +6,50 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"math/rand"
 	"neuraldeep/activation"
 	"neuraldeep/cost"
+	"neuraldeep/preprocess"
+	"neuraldeep/regularization"
 	"neuraldeep/utils/matrix"
 	"neuraldeep/utils/python"
+	"neuraldeep/utils/tensor"
 	"os"
+	"time"
 
 	"gonum.org/v1/gonum/mat"
 )
 
 //--- TYPES
 
+// EpochHook is called by SGD() at the end of each epoch with the values monitored during it.
+type EpochHook func(e Epoch)
+
+// Augmentation returns a distorted copy of the input 'x', drawing its random parameters from 'rng'.
+type Augmentation func(x *Input, rng *rand.Rand) *Input
+
 // Network2 ...
 type Network2 struct {
-	Sizes     []int
-	Cost      cost.Cost
-	numLayers int
-	weights   []mat.Matrix
-	biases    []mat.Matrix
+	Sizes       []int
+	Cost        cost.Cost
+	Regularizer regularization.Regularizer
+	numLayers   int
+	weights     []mat.Matrix
+	biases      []mat.Matrix
+	dropout     []float64
+	initializer string
+	stoppingN   int
+	augment     Augmentation
+	preprocess  preprocess.Pipeline
+	batchNorm   []*BatchNorm
+	recorder    *gradientRecorder
+	hooks       []EpochHook
+	buffers     *workspace
+	buffers32   *workspace32
+	precision   string
+	weights32   []*tensor.Tensor
+	biases32    []*tensor.Tensor
+	rng         *rand.Rand
 }
 
 //--- METHODS
@@ -57,7 +83,12 @@ func (net *Network2) Accuracy(data Dataset) (sum int) {
 
 // Backprop returns a tuple representing the gradient of the cost function `C_x`.
 // 'biasesByLayer' and 'weightsByLayer' are layer-by-layer lists of matrices, similar to `Network.biases` and `Network.weights`.
+// With batch normalization, the input is treated as a mini-batch of one: use BackpropBatch() instead.
 func (net *Network2) Backprop(x *Input) (biasesByLayer, weightsByLayer []mat.Matrix) {
+	if net.batchNorm != nil {
+		biasesByLayer, weightsByLayer, _, _ = net.BackpropBatch(Dataset{x})
+		return
+	}
 	for _, b := range net.biases {
 		r, c := b.Dims()
 		data := make([]float64, r*c)
@@ -73,11 +104,16 @@ func (net *Network2) Backprop(x *Input) (biasesByLayer, weightsByLayer []mat.Mat
 	// Feedforward
 	activations := []mat.Matrix{x.ToVector().T()}
 	zs := []mat.Matrix{}
+	masks := make([]mat.Matrix, net.NumLayers()-1)
 	activatn := activations[0]
 	for i := 0; i < net.NumLayers()-1; i++ {
 		z := matrix.Add(matrix.Dot(net.weights[i], activatn.T()).T(), net.biases[i])
 		zs = append(zs, z)
 		activatn = matrix.Apply(activation.Sigmoid, z)
+		if mask := net.dropoutMask(i, z); mask != nil {
+			masks[i] = mask
+			activatn = matrix.Multiply(activatn, mask)
+		}
 		activations = append(activations, activatn)
 	}
 	// Backward pass
@@ -85,9 +121,12 @@ func (net *Network2) Backprop(x *Input) (biasesByLayer, weightsByLayer []mat.Mat
 	biasesByLayer[len(biasesByLayer)-1] = delta
 	weightsByLayer[len(weightsByLayer)-1] = matrix.Dot(delta.T(), activations[len(activations)-2])
 	if net.NumLayers() > 2 {
-		for l := range python.XRange(2, net.NumLayers()-1, 1) {
+		for l := 2; l < net.NumLayers(); l++ {
 			z := zs[len(zs)-l]
-			sp := matrix.Apply(activation.Sigmoid, z)
+			sp := matrix.Apply(activation.SigmoidPrime, z)
+			if mask := masks[len(zs)-l]; mask != nil {
+				sp = matrix.Multiply(sp, mask)
+			}
 			delta = matrix.Multiply(matrix.Dot(delta, net.weights[len(net.weights)-l+1]), sp)
 			biasesByLayer[len(biasesByLayer)-l] = delta
 			weightsByLayer[len(weightsByLayer)-l] = matrix.Dot(delta.T(), activations[len(activations)-l-1])
@@ -96,12 +135,29 @@ func (net *Network2) Backprop(x *Input) (biasesByLayer, weightsByLayer []mat.Mat
 	return
 }
 
+// Clone returns a copy of the network as Save() would write it, ie. leaving out its training settings such as
+// the early stopping, the augmentation or the hooks, eg. to keep the parameters of the best epoch of a training.
+func (net *Network2) Clone() (*Network2, error) {
+	bytes, err := net.marshal()
+	if err != nil {
+		return nil, err
+	}
+	clone := &Network2{}
+	return clone, clone.unmarshal(bytes)
+}
+
 // FeedForward returns the output of the network if `a` is input.
+// Dropout is never applied here: thanks to inverted dropout during training, the weights are already scaled for inference.
+// Batch normalization, if any, uses the running statistics gathered during training.
+// In float32 precision (see SetPrecision()), the computations use the float32 parameters.
 func (net *Network2) FeedForward(a mat.Vector) (output mat.Matrix) {
+	if net.weights32 != nil {
+		return net.feedForward32(a)
+	}
 	output = a.T()
 	for i := 0; i < net.NumLayers()-1; i++ {
 		// sigmoid(w·a + b)
-		output = matrix.Apply(activation.Sigmoid, matrix.Add(matrix.Dot(net.weights[i], output.T()).T(), net.biases[i]))
+		output = matrix.Apply(activation.Sigmoid, net.normalize(i, matrix.Add(matrix.Dot(net.weights[i], output.T()).T(), net.biases[i])))
 	}
 	return output
 }
@@ -112,28 +168,39 @@ func (net *Network2) Load(path string) error {
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 	bytes, err := ioutil.ReadAll(f)
 	if err != nil {
 		return err
 	}
+	return net.unmarshal(bytes)
+}
+
+// unmarshal replaces the current Network2 instance by the network encoded in JSON by marshal().
+func (net *Network2) unmarshal(bytes []byte) error {
 	var n Network
-	err = json.Unmarshal(bytes, &n)
+	err := json.Unmarshal(bytes, &n)
 	if err != nil {
 		return err
 	}
-	var c cost.Cost
-	switch n.Cost {
-	case cost.CROSS_ENTROPY:
-		c = cost.CrossEntropyCost{}
-	case cost.QUADRATIC_COST:
-		c = cost.QuadraticCost{}
-	default:
+	c, err := cost.New(n.Cost)
+	if err != nil {
 		return errors.New("invalid cost function")
 	}
 	n2, err := Initial(n.Sizes, c)
 	if err != nil {
 		return err
 	}
+	if n.Regularizer != "" {
+		if n2.Regularizer, err = regularization.New(n.Regularizer); err != nil {
+			return err
+		}
+	}
+	if n.Precision == FLOAT32 {
+		if n.Weights, n.Biases, err = float64s(n.Weights32, n.Biases32, n2); err != nil {
+			return err
+		}
+	}
 	for i, wData := range n.Weights {
 		r, c := n2.weights[i].Dims()
 		mW := mat.NewDense(r, c, wData)
@@ -144,16 +211,48 @@ func (net *Network2) Load(path string) error {
 		mB := mat.NewDense(r, c, bData)
 		n2.biases[i] = mB
 	}
+	if err = n2.SetDropout(n.Dropout); err != nil {
+		return err
+	}
+	if n.BatchNorm != nil {
+		if err = validateBatchNorm(n.BatchNorm, n.Sizes); err != nil {
+			return err
+		}
+	}
 	net.Sizes = n2.Sizes
 	net.Cost = n2.Cost
+	net.Regularizer = n2.Regularizer
 	net.numLayers = n2.NumLayers()
 	net.weights = n2.weights
 	net.biases = n2.biases
-	return nil
+	net.dropout = n2.dropout
+	net.initializer = n.Initializer
+	net.preprocess = n.Preprocessing
+	net.batchNorm = n.BatchNorm
+	if net.rng == nil {
+		net.rng = n2.rng
+	}
+	if n.Precision == "" {
+		n.Precision = FLOAT64
+	}
+	return net.SetPrecision(n.Precision)
 }
 
-// Save saves the neural network to the file 'path'.
+// Save saves the neural network to the file 'path'. In float32 precision, only the float32 parameters are saved.
 func (net *Network2) Save(path string) error {
+	jsonNetwork, err := net.marshal()
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(path, jsonNetwork, 0644)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// marshal encodes the neural network in JSON, as saved by Save().
+func (net *Network2) marshal() ([]byte, error) {
 	var wList [][]float64
 	for _, weights := range net.weights {
 		var wL []float64
@@ -177,20 +276,25 @@ func (net *Network2) Save(path string) error {
 		bList = append(bList, bL)
 	}
 	data := Network{
-		Sizes:   net.Sizes,
-		Cost:    net.Cost.GetName(),
-		Weights: wList,
-		Biases:  bList,
-	}
-	jsonNetwork, err := json.Marshal(data)
-	if err != nil {
-		return err
-	}
-	err = ioutil.WriteFile(path, jsonNetwork, 0644)
-	if err != nil {
-		return err
+		Sizes:         net.Sizes,
+		Precision:     net.precision,
+		Cost:          net.Cost.GetName(),
+		Regularizer:   net.Regularizer.GetName(),
+		Weights:       wList,
+		Biases:        bList,
+		Dropout:       net.dropout,
+		Initializer:   net.initializer,
+		Preprocessing: net.preprocess,
+		BatchNorm:     net.batchNorm,
+	}
+	if net.weights32 != nil {
+		for i, w := range net.weights32 {
+			data.Weights32 = append(data.Weights32, w.Data)
+			data.Biases32 = append(data.Biases32, net.biases32[i].Data)
+		}
+		data.Weights, data.Biases = nil, nil
 	}
-	return nil
+	return json.Marshal(data)
 }
 
 // SGD trains the neural network using mini-batch stochastic gradient descent.
@@ -203,10 +307,17 @@ func (net *Network2) Save(path string) error {
 // then the first list will be a 30-element list containing the cost on the evaluation data at the end of each epoch.
 // Note that the lists are empty if the corresponding flag is not set. These flags are set as boolean values in the 'monitors' parameter
 // in the following order: 'monitorEvaluationCost', 'monitorEvaluationAccuracy', 'monitorTrainingCost', 'monitorTrainingAccuracy'.
-func (net *Network2) SGD(training Dataset, epochs, miniBatchSize int, eta, lambda float64, evaluation Dataset, monitors ...bool) (evaluationCost []float64, evaluationAccuracy []int, trainingCost []float64, trainingAccuracy []int) {
+// A fifth flag, 'monitorGradients', makes the method also return the learning speed of each layer at each epoch (see LayerGradients).
+// If early stopping is set (see SetEarlyStopping()), training ends as soon as the accuracy on the evaluation data hasn't improved
+// for the configured number of epochs, in which case the lists are shorter than 'epochs'.
+// The hooks registered with OnEpoch() are called at the end of each epoch with the values monitored during it.
+// If an augmentation is set (see SetAugmentation()), the gradient descent uses distorted copies of the training inputs
+// while the monitoring still uses the original data.
+// In float32 precision (see SetPrecision()), each mini-batch is backpropagated at once with float32 tensors.
+func (net *Network2) SGD(training Dataset, epochs, miniBatchSize int, eta, lambda float64, evaluation Dataset, monitors ...bool) (evaluationCost []float64, evaluationAccuracy []int, trainingCost []float64, trainingAccuracy []int, gradients []LayerGradients) {
 	var (
-		nData, n                                                                                       int
-		monitorEvaluationCost, monitorEvaluationAccuracy, monitorTrainingCost, monitorTrainingAccuracy bool
+		nData, n                                                                                                         int
+		monitorEvaluationCost, monitorEvaluationAccuracy, monitorTrainingCost, monitorTrainingAccuracy, monitorGradients bool
 	)
 	if len(evaluation) > 0 {
 		nData = len(evaluation)
@@ -221,20 +332,50 @@ func (net *Network2) SGD(training Dataset, epochs, miniBatchSize int, eta, lambd
 	if len(monitors) > 2 {
 		monitorTrainingCost = monitors[2]
 	}
-	if len(monitors) == 4 {
+	if len(monitors) > 3 {
 		monitorTrainingAccuracy = monitors[3]
 	}
+	if len(monitors) > 4 {
+		monitorGradients = monitors[4]
+	}
+	defer func() {
+		net.recorder = nil
+	}()
+	bestAccuracy, noImprovement := -1, 0
 	for j := 0; j < epochs; j++ {
-		training.Shuffle()
-		var miniBatches []Dataset
-		for k := range python.XRange(0, n-miniBatchSize, miniBatchSize) {
-			miniBatch := training[k : k+miniBatchSize]
-			miniBatches = append(miniBatches, miniBatch)
+		start := time.Now()
+		if monitorGradients {
+			net.recorder = newGradientRecorder(net.NumLayers())
 		}
-		for _, miniBatch := range miniBatches {
-			net.UpdateMiniBatch(miniBatch, eta, lambda, n)
+		training.Shuffle(net.rng)
+		samples := 0
+		for _, miniBatch := range training.MiniBatches(miniBatchSize) {
+			samples += len(miniBatch)
+			if net.augment != nil {
+				augmented := make(Dataset, len(miniBatch))
+				for i, input := range miniBatch {
+					augmented[i] = net.augment(input, net.rng)
+				}
+				miniBatch = augmented
+			}
+			if net.weights32 != nil {
+				net.updateMiniBatch32(miniBatch, eta, lambda, n)
+			} else {
+				net.UpdateMiniBatch(miniBatch, eta, lambda, n)
+			}
 		}
+		if net.weights32 != nil {
+			net.fromFloat32()
+		}
+		duration := time.Since(start)
 		fmt.Printf("epoch %d complete\n", j+1)
+		if monitorGradients {
+			lg := net.recorder.result()
+			gradients = append(gradients, lg)
+			for l := range lg.MeanDelta {
+				fmt.Printf("learning speed of layer %d: mean |δ| = %.3e, ‖Δw‖ = %.3e\n", l+1, lg.MeanDelta[l], lg.WeightUpdateNorm[l])
+			}
+		}
 		if monitorTrainingCost {
 			tc := net.TotalCost(training, lambda)
 			trainingCost = append(trainingCost, tc)
@@ -256,22 +397,45 @@ func (net *Network2) SGD(training Dataset, epochs, miniBatchSize int, eta, lambd
 			fmt.Printf("accuracy on evaluation data: %d / %d\n", ea, nData)
 		}
 		fmt.Println("")
+		if len(net.hooks) > 0 {
+			record := epochAt(j, evaluationCost, evaluationAccuracy, trainingCost, trainingAccuracy, n, nData)
+			record.Duration = duration.Seconds()
+			record.Samples = samples
+			record.Eta = eta
+			for _, hook := range net.hooks {
+				hook(record)
+			}
+		}
+		if net.stoppingN > 0 && len(evaluation) > 0 {
+			var accuracy int
+			if monitorEvaluationAccuracy {
+				accuracy = evaluationAccuracy[len(evaluationAccuracy)-1]
+			} else {
+				accuracy = net.Accuracy(evaluation)
+			}
+			if accuracy > bestAccuracy {
+				bestAccuracy = accuracy
+				noImprovement = 0
+			} else {
+				noImprovement++
+			}
+			if noImprovement == net.stoppingN {
+				fmt.Printf("early-stopping: no accuracy change in last epochs: %d\n\n", net.stoppingN)
+				break
+			}
+		}
 	}
 	return
 }
 
-// TotalCost returns the total cost for the data set 'data'.
+// TotalCost returns the total cost for the data set 'data', including the weight penalty of the network's regularizer.
 func (net *Network2) TotalCost(data Dataset, lambda float64) (c float64) {
 	for _, input := range data {
 		x := input.ToVector()
 		a := net.FeedForward(x)
 		c += net.Cost.Function(a, input.Label.Vector) / float64(len(data))
 	}
-	sum := 0.
-	for _, w := range net.weights {
-		sum += math.Pow(mat.Norm(w, 2), 2)
-	}
-	c += 0.5 * (lambda / float64(len(data))) * sum
+	c += net.Regularizer.Cost(net.weights, lambda, len(data))
 	return
 }
 
@@ -279,40 +443,117 @@ func (net *Network2) TotalCost(data Dataset, lambda float64) (c float64) {
 // using backpropagation to a single mini batch.
 // The 'miniBatch' is a list of `Inputs`, 'eta' is the learning rate, 'lambda' is the
 // regularization parameter, and 'n' is the total size of the training data set.
+// The weight penalty itself is delegated to the network's regularizer.
+// With batch normalization, the gradients are computed on the whole mini-batch at once. Otherwise, they are computed
+// in the buffers of the network's workspace and the parameters are updated in place.
 func (net *Network2) UpdateMiniBatch(miniBatch Dataset, eta, lambda float64, n int) {
-	var biasesByLayer []mat.Matrix
-	for _, b := range net.biases {
-		r, c := b.Dims()
-		data := make([]float64, r*c)
-		biasesLayer := mat.NewDense(r, c, data)
-		biasesByLayer = append(biasesByLayer, biasesLayer)
-	}
-	var weightsByLayer []mat.Matrix
-	for _, w := range net.weights {
-		r, c := w.Dims()
-		data := make([]float64, r*c)
-		weightsLayer := mat.NewDense(r, c, data)
-		weightsByLayer = append(weightsByLayer, weightsLayer)
+	if net.weights32 != nil {
+		net.updateMiniBatch32(miniBatch, eta, lambda, n)
+		net.fromFloat32()
+		return
 	}
-	for _, input := range miniBatch {
-		deltaBiasesByLayer, deltaWeightsByLayer := net.Backprop(input)
-		for i, biases := range biasesByLayer {
-			biasesByLayer[i] = matrix.Add(biases, deltaBiasesByLayer[i])
+	if net.recorder != nil {
+		net.recorder.before(net.weights)
+		defer func() {
+			net.recorder.after(net.weights)
+		}()
+	}
+	if net.batchNorm != nil {
+		biasesByLayer, weightsByLayer, gammasByLayer, betasByLayer, cache := net.backpropBatch(miniBatch)
+		if net.recorder != nil {
+			for l, deltas := range cache.deltas {
+				net.recorder.addDeltas(l, deltas)
+			}
+		}
+		for i, biases := range net.biases {
+			net.biases[i] = matrix.Subtract(biases, matrix.Scale(eta/float64(len(miniBatch)), biasesByLayer[i]))
 		}
-		for i, weights := range weightsByLayer {
-			weightsByLayer[i] = matrix.Add(weights, deltaWeightsByLayer[i])
+		for i, weights := range net.weights {
+			net.weights[i] = matrix.Subtract(net.Regularizer.Update(weights, eta, lambda, n), matrix.Scale(eta/float64(len(miniBatch)), weightsByLayer[i]))
 		}
+		net.updateBatchNorm(gammasByLayer, betasByLayer, cache, eta, len(miniBatch))
+		return
+	}
+	if net.buffers == nil || !net.buffers.fits(net.Sizes) {
+		net.buffers = newWorkspace(net.Sizes)
 	}
-	for i, biases := range net.biases {
-		net.biases[i] = matrix.Subtract(biases, matrix.Scale(eta/float64(len(miniBatch)), biasesByLayer[i]))
+	ws := net.buffers
+	ws.reset()
+	last := len(net.weights) - 1
+	for _, input := range miniBatch {
+		ws.forward(net.weights, net.biases, input, net.fillDropoutMask)
+		net.outputDelta(ws.deltas[last], ws.activations[last], ws.zs[last], input.Label.Vector)
+		ws.backward(net.weights)
+		if net.recorder != nil {
+			for l, deltas := range ws.deltas {
+				net.recorder.addDeltas(l, deltas)
+			}
+		}
 	}
+	k := eta / float64(len(miniBatch))
 	for i, weights := range net.weights {
-		net.weights[i] = matrix.Subtract(matrix.Scale(1-eta*(lambda/float64(n)), weights), matrix.Scale(eta/float64(len(miniBatch)), weightsByLayer[i]))
+		net.weights[i] = penalize(net.Regularizer, weights, eta, lambda, n)
+	}
+	descend(net.biases, ws.sumB, k)
+	descend(net.weights, ws.sumW, k)
+}
+
+// outputDelta writes into 'dst' the error of the output layer of activations 'a' and weighted inputs 'z' for the target 'y'.
+// The quadratic and cross-entropy costs are computed in place, the other ones through their Delta() method.
+func (net *Network2) outputDelta(dst, a, z *mat.Dense, y mat.Vector) {
+	_, c := a.Dims()
+	switch net.Cost.GetName() {
+	case cost.CROSS_ENTROPY:
+		for j := 0; j < c; j++ {
+			dst.Set(0, j, a.At(0, j)-y.AtVec(j))
+		}
+	case cost.QUADRATIC_COST:
+		for j := 0; j < c; j++ {
+			dst.Set(0, j, (a.At(0, j)-y.AtVec(j))*activation.SigmoidPrime(0, j, z.At(0, j)))
+		}
+	default:
+		dst.Copy(net.Cost.Delta(a, y, z))
 	}
 }
 
 //---
 
+// Dropout returns the dropout rate of each hidden layer, or nil if dropout is disabled.
+func (net *Network2) Dropout() []float64 {
+	return net.dropout
+}
+
+// Initialize draws new biases and weights using the weight initializer registered under the passed name,
+// eg. `XAVIER_INITIALIZER`, and records it as part of the network's metadata.
+// If a random generator is passed, they are drawn from it instead of the global one.
+func (net *Network2) Initialize(name string, rng ...*rand.Rand) error {
+	initializer, err := NewInitializer(name)
+	if err != nil {
+		return err
+	}
+	biases, weights, err := initializer(net.Sizes, rng...)
+	if err != nil {
+		return err
+	}
+	net.biases = biases
+	net.weights = weights
+	net.initializer = name
+	if net.weights32 != nil {
+		net.toFloat32()
+	}
+	return nil
+}
+
+// Initializer returns the name of the weight initializer used to create the network.
+func (net *Network2) Initializer() string {
+	return net.initializer
+}
+
+// Loss returns the cost `C_x` of the network's cost function for the single input 'x', without any regularization term.
+func (net *Network2) Loss(x *Input) float64 {
+	return net.Cost.Function(net.FeedForward(x.ToVector()), x.Label.Vector)
+}
+
 // NumLayers is utility method returning the number of layers in the network.
 func (net *Network2) NumLayers() int {
 	return net.numLayers
@@ -323,6 +564,118 @@ func (net *Network2) OutputSize() int {
 	return net.Sizes[net.NumLayers()-1]
 }
 
+// OnEpoch registers a hook to call at the end of each epoch of SGD(), eg. to stream the training metrics.
+func (net *Network2) OnEpoch(hook EpochHook) {
+	net.hooks = append(net.hooks, hook)
+}
+
+// Parameters returns the biases and weights of the network layer by layer.
+// Beware that these are the actual matrices used by the network and not copies, except in float32 precision
+// where they are float64 copies of the parameters refreshed at the end of each epoch.
+func (net *Network2) Parameters() (biases, weights []mat.Matrix) {
+	return net.biases, net.weights
+}
+
+// Predict returns the output of the network for the raw input 'x', applying the network's preprocessing beforehand if any.
+func (net *Network2) Predict(x []float64) mat.Matrix {
+	input := &Input{Data: x}
+	if net.preprocess != nil {
+		input.Data = net.preprocess.Transform(x)
+	}
+	return net.FeedForward(input.ToVector())
+}
+
+// Preprocess returns a copy of the passed dataset whose inputs are transformed by the network's preprocessing pipeline,
+// or the dataset itself if there's none. The labels are shared with the original inputs.
+func (net *Network2) Preprocess(data Dataset) Dataset {
+	if net.preprocess == nil {
+		return data
+	}
+	return data.Transform(net.preprocess)
+}
+
+// Preprocessing returns the pipeline transforming the raw inputs of the network, or nil if there's none.
+func (net *Network2) Preprocessing() preprocess.Pipeline {
+	return net.preprocess
+}
+
+// Seed resets the training random generator used to shuffle the data and draw the dropout masks.
+func (net *Network2) Seed(seed int64) {
+	net.rng = rand.New(rand.NewSource(seed))
+}
+
+// SetEarlyStopping makes SGD() stop when the accuracy on the evaluation data hasn't improved in the last 'n' epochs.
+// Setting 'n' to 0 disables early stopping.
+func (net *Network2) SetEarlyStopping(n int) {
+	net.stoppingN = n
+}
+
+// SetAugmentation makes SGD() train on a distorted copy of each mini-batch, drawn anew at every epoch from the training random generator.
+// Passing nil disables the augmentation.
+func (net *Network2) SetAugmentation(fn Augmentation) {
+	net.augment = fn
+}
+
+// SetPreprocessing sets the fitted pipeline used to transform the raw inputs, so that it's saved along with the network.
+// Note that FeedForward() and the training methods expect inputs that are already transformed: see Preprocess() and Predict().
+func (net *Network2) SetPreprocessing(p preprocess.Pipeline) {
+	net.preprocess = p
+}
+
+// SetDropout sets the probability of dropping each neuron of the hidden layers during training.
+// The 'rates' list must hold one value in [0, 1) per hidden layer, eg. [0.2, 0.5] for a 784-100-30-10 network;
+// passing an empty list disables dropout. Dropout isn't available in float32 precision.
+func (net *Network2) SetDropout(rates []float64) error {
+	if len(rates) == 0 {
+		net.dropout = nil
+		return nil
+	}
+	if net.weights32 != nil {
+		return errors.New("dropout isn't available in float32 precision")
+	}
+	if len(rates) != net.NumLayers()-2 {
+		return fmt.Errorf("expected %d dropout rates, one per hidden layer, got %d", net.NumLayers()-2, len(rates))
+	}
+	for _, p := range rates {
+		if p < 0 || p >= 1 {
+			return fmt.Errorf("invalid dropout rate: %f", p)
+		}
+	}
+	net.dropout = rates
+	return nil
+}
+
+// dropoutMask returns the inverted dropout mask to apply to the activations of the layer fed by `net.weights[i]`,
+// ie. a row of zeros for the dropped neurons and of 1/(1-p) for the kept ones, or nil if the layer isn't concerned.
+func (net *Network2) dropoutMask(i int, z mat.Matrix) mat.Matrix {
+	if i >= len(net.dropout) || net.dropout[i] == 0 {
+		return nil
+	}
+	p := net.dropout[i]
+	return matrix.Apply(func(_, _ int, _ float64) float64 {
+		if net.rng.Float64() < p {
+			return 0
+		}
+		return 1 / (1 - p)
+	}, z)
+}
+
+// fillDropoutMask writes into 'dst' the inverted dropout mask of the layer fed by `net.weights[i]`, see dropoutMask(),
+// and returns `false` if the layer isn't concerned.
+func (net *Network2) fillDropoutMask(i int, dst *mat.Dense) bool {
+	if i >= len(net.dropout) || net.dropout[i] == 0 {
+		return false
+	}
+	p := net.dropout[i]
+	update(dst, func(float64) float64 {
+		if net.rng.Float64() < p {
+			return 0
+		}
+		return 1 / (1 - p)
+	})
+	return true
+}
+
 //--- FUNCTIONS
 
 // Initial ...
@@ -330,11 +683,22 @@ func (net *Network2) OutputSize() int {
 // For example, if the list was [2, 3, 1] then it would be a three-layer network, with the
 // first layer containing 2 neurons, the second layer 3 neurons, and the third layer 1 neuron.
 // The biases and weights for the network are initialized randomly, using DefaultWeightInitializer().
+// The network is regularized with L2 by default: assign another `Regularizer` to change it.
 func Initial(sizes []int, fn ...cost.Cost) (n *Network2, err error) {
+	return InitialWith(sizes, DEFAULT_INITIALIZER, nil, fn...)
+}
+
+// InitialWith is Initial() with the biases and weights drawn by the weight initializer registered under the passed name,
+// eg. `XAVIER_INITIALIZER`, from 'rng' if it isn't nil so that they can be reproduced, or from the global generator otherwise.
+func InitialWith(sizes []int, initializer string, rng *rand.Rand, fn ...cost.Cost) (n *Network2, err error) {
 	if len(sizes) < 2 {
 		err = errors.New("not enough layers")
 		return
 	}
+	initialize, err := NewInitializer(initializer)
+	if err != nil {
+		return
+	}
 
 	var costFunction cost.Cost
 	if len(fn) != 1 {
@@ -342,31 +706,35 @@ func Initial(sizes []int, fn ...cost.Cost) (n *Network2, err error) {
 	} else {
 		costFunction = fn[0]
 	}
+	regularizer, _ := regularization.New(regularization.L2)
 
-	biases, weights, err := DefaultWeightInitializer(sizes)
+	biases, weights, err := initialize(sizes, rng)
 	if err != nil {
 		return
 	}
 
 	return &Network2{
-		Sizes:     sizes,
-		Cost:      costFunction,
-		numLayers: len(sizes),
-		weights:   weights,
-		biases:    biases,
+		Sizes:       sizes,
+		Cost:        costFunction,
+		Regularizer: regularizer,
+		numLayers:   len(sizes),
+		weights:     weights,
+		biases:      biases,
+		initializer: initializer,
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
 	}, nil
 }
 
-// Initialize each weight using a Gaussian distribution with mean 0 and standard deviation 1
+// DefaultWeightInitializer initializes each weight using a Gaussian distribution with mean 0 and standard deviation 1
 // over the square root of the number of weights connecting to the same neuron.
 // Initialize the biases using a Gaussian distribution with mean 0 and standard deviation 1.
 // Note that the first layer is assumed to be an input layer, and by convention we won't set
 // any biases for those neurons, since biases are only ever used in computing the outputs from later layers.
-func DefaultWeightInitializer(sizes []int) (biases, weights []mat.Matrix, err error) {
+func DefaultWeightInitializer(sizes []int, rng ...*rand.Rand) (biases, weights []mat.Matrix, err error) {
 	// Biases
 	bs := make([]mat.Matrix, len(sizes)-1)
 	for i, size := range sizes[1:] {
-		bs[i] = matrix.Random(1, size, 2.)
+		bs[i] = matrix.Gaussian(1, size, 0, 1, rng...)
 	}
 
 	// Weights
@@ -376,29 +744,22 @@ func DefaultWeightInitializer(sizes []int) (biases, weights []mat.Matrix, err er
 	}
 	ws := make([]mat.Matrix, len(tuples))
 	for i, tuple := range tuples {
-		rand := matrix.Random(tuple.J, tuple.I, 2.)
-		ws[i] = matrix.Apply(func(i, j int, v float64) float64 {
-			x := float64(tuple.I)
-			if x < 0 {
-				x = -x
-			}
-			return v / math.Sqrt(x)
-		}, rand)
+		ws[i] = matrix.Gaussian(tuple.J, tuple.I, 0, 1/math.Sqrt(float64(tuple.I)), rng...)
 	}
 	return bs, ws, nil
 }
 
-// Initialize the weights using a Gaussian distribution with mean 0 and standard deviation 1.
+// LargeWeightInitializer initializes the weights using a Gaussian distribution with mean 0 and standard deviation 1.
 // Initialize the biases using a Gaussian distribution with mean 0 and standard deviation 1.
 // Note that the first layer is assumed to be an input layer, and by convention we won't set any biases
 // for those neurons, since biases are only ever used in computing the outputs from later layers.
 // This weight and bias initializer uses the same approach as in Chapter 1, and is included for purposes of comparison.
 // It will usually be better to use the default weight initializer instead.
-func LargeWeightInitializer(sizes []int) (biases, weights []mat.Matrix, err error) {
+func LargeWeightInitializer(sizes []int, rng ...*rand.Rand) (biases, weights []mat.Matrix, err error) {
 	// Biases
 	bs := make([]mat.Matrix, len(sizes)-1)
 	for i, size := range sizes[1:] {
-		bs[i] = matrix.Random(1, size, 2.)
+		bs[i] = matrix.Gaussian(1, size, 0, 1, rng...)
 	}
 
 	// Weights
@@ -408,7 +769,7 @@ func LargeWeightInitializer(sizes []int) (biases, weights []mat.Matrix, err erro
 	}
 	ws := make([]mat.Matrix, len(tuples))
 	for i, tuple := range tuples {
-		ws[i] = matrix.Random(tuple.J, tuple.I, 2.)
+		ws[i] = matrix.Gaussian(tuple.J, tuple.I, 0, 1, rng...)
 	}
 	return bs, ws, nil
 }
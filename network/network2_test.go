@@ -0,0 +1,63 @@
+package network_test
+
+import (
+	"math"
+	"neuraldeep/activation"
+	"neuraldeep/network"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestDropout checks that the inverted dropout drops about 'p' of the hidden neurons during training, scales the kept
+// ones by 1/(1-p), and leaves the inference alone.
+func TestDropout(t *testing.T) {
+	const p = 0.4
+	data := randomDataset(10, 4, 2)
+	net, err := network.Initial([]int{4, 1000, 2})
+	assert.NilError(t, err)
+	assert.NilError(t, net.SetDropout([]float64{p}))
+	net.Seed(1)
+
+	// The gradient of the output weights is the output error times the masked hidden activations: dividing it by the
+	// error, ie. the gradient of the output biases, and by the hidden activations gives back the mask
+	biases, weights := net.Parameters()
+	x := data[0]
+	nablaB, nablaW := net.Backprop(x)
+	dropped := 0
+	for k := 0; k < 1000; k++ {
+		z := biases[0].At(0, k)
+		for i, v := range x.Data {
+			z += weights[0].At(k, i) * v
+		}
+		mask := nablaW[1].At(0, k) / (nablaB[1].At(0, 0) * activation.Sigmoid(0, k, z))
+		if mask == 0 {
+			dropped++
+		} else {
+			assert.Assert(t, math.Abs(mask-1/(1-p)) < 1e-9, "neuron %d: %g", k, mask)
+		}
+	}
+	assert.Assert(t, dropped > 350 && dropped < 450, "%d dropped", dropped)
+
+	// A new mask is drawn for each input
+	_, again := net.Backprop(x)
+	assert.Assert(t, !mat.Equal(again[1], nablaW[1]))
+
+	// Inference is deterministic and doesn't depend on the dropout
+	output := net.FeedForward(x.ToVector())
+	assert.Assert(t, mat.Equal(net.FeedForward(x.ToVector()), output))
+	assert.NilError(t, net.SetDropout(nil))
+	assert.Assert(t, mat.Equal(net.FeedForward(x.ToVector()), output))
+
+	// The in-place training draws the same masks as Backprop() for the same seed
+	assert.NilError(t, net.SetDropout([]float64{p}))
+	net.Seed(2)
+	expected := descent(net, data, 0.5)
+	net.Seed(2)
+	net.UpdateMiniBatch(data, 0.5, 0, len(data))
+	assertParameters(t, net, expected)
+
+	assert.Error(t, net.SetDropout([]float64{0.5, 0.5}), "expected 1 dropout rates, one per hidden layer, got 2")
+	assert.Error(t, net.SetDropout([]float64{1}), "invalid dropout rate: 1.000000")
+}
@@ -0,0 +1,56 @@
+package network
+
+import (
+	"math/rand"
+)
+
+//--- TYPES
+
+// Sequence is a list of inputs fed one after the other to a recurrent network, each one labelled with the desired output
+// at its step, eg. the next character of a text.
+type Sequence []*Input
+
+// SequenceDataset ...
+type SequenceDataset []Sequence
+
+//--- METHODS
+
+// MiniBatches splits the dataset into consecutive mini-batches of 'size' sequences, the last one holding the remaining ones.
+func (ds SequenceDataset) MiniBatches(size int) []SequenceDataset {
+	return miniBatches(ds, size)
+}
+
+// MiniBatchesByLength splits the dataset into mini-batches of at most 'size' sequences of the same length, eg. for
+// a recurrent network feeding the steps of a mini-batch together. The lengths come in the order of their first
+// sequence and the sequences keep their order within each length. Empty sequences, with nothing to learn, are left out.
+func (ds SequenceDataset) MiniBatchesByLength(size int) (batches []SequenceDataset) {
+	var lengths []int
+	byLength := make(map[int]SequenceDataset)
+	for _, s := range ds {
+		if len(s) == 0 {
+			continue
+		}
+		if _, exists := byLength[len(s)]; !exists {
+			lengths = append(lengths, len(s))
+		}
+		byLength[len(s)] = append(byLength[len(s)], s)
+	}
+	for _, length := range lengths {
+		batches = append(batches, byLength[length].MiniBatches(size)...)
+	}
+	return
+}
+
+// Shuffle randomly reorders the sequences of the dataset in place, the order of the steps of each sequence being kept.
+// If a random generator is passed, it is used instead of a time-seeded one so that the order is reproducible.
+func (ds SequenceDataset) Shuffle(rng ...*rand.Rand) {
+	shuffle(ds, rng...)
+}
+
+// Steps returns the number of steps of all the sequences of the dataset.
+func (ds SequenceDataset) Steps() (n int) {
+	for _, s := range ds {
+		n += len(s)
+	}
+	return
+}
@@ -0,0 +1,181 @@
+package network
+
+import (
+	"neuraldeep/activation"
+	"neuraldeep/regularization"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// The backpropagation of each input of a mini-batch goes through matrices of the same dimensions: instead of allocating
+// them again for every input and every layer, UpdateMiniBatch() writes them into the buffers of a workspace kept by the
+// network, so that the garbage collector no longer dominates the training time. Backprop() keeps returning new matrices.
+
+//--- TYPES
+
+// workspace holds the buffers of the backpropagation of one input, layer by layer, and the sums of the gradients
+// over the current mini-batch, each list having one item per layer of weights.
+type workspace struct {
+	input       *mat.Dense
+	zs          []*mat.Dense
+	activations []*mat.Dense
+	masks       []*mat.Dense
+	masked      []bool
+	sps         []*mat.Dense
+	deltas      []*mat.Dense
+	inputVec    *mat.VecDense
+	zVecs       []*mat.VecDense
+	actVecs     []*mat.VecDense
+	deltaVecs   []*mat.VecDense
+	sumB        []*mat.Dense
+	sumW        []*mat.Dense
+}
+
+//--- METHODS
+
+// activation returns the activation of the layer of index 'l', the input being of index 0.
+func (ws *workspace) activation(l int) *mat.Dense {
+	if l == 0 {
+		return ws.input
+	}
+	return ws.activations[l-1]
+}
+
+// activationVec returns the activation of the layer of index 'l' as a vector sharing its data.
+func (ws *workspace) activationVec(l int) *mat.VecDense {
+	if l == 0 {
+		return ws.inputVec
+	}
+	return ws.actVecs[l-1]
+}
+
+// backward propagates the error of the output layer, already in the last item of `ws.deltas`, through the hidden layers,
+// then adds the resulting gradients to the sums of the mini-batch.
+func (ws *workspace) backward(weights []mat.Matrix) {
+	last := len(weights) - 1
+	for l := last - 1; l >= 0; l-- {
+		matrix.ApplyTo(ws.sps[l], activation.SigmoidPrime, ws.zs[l])
+		if ws.masked[l] {
+			matrix.MultiplyTo(ws.sps[l], ws.sps[l], ws.masks[l])
+		}
+		matrix.DotTo(ws.deltas[l], ws.deltas[l+1], weights[l+1])
+		matrix.MultiplyTo(ws.deltas[l], ws.deltas[l], ws.sps[l])
+	}
+	// ∇w = δᵀ·a being an outer product, it's added to the sum without going through a matrix of its own
+	for l := range weights {
+		matrix.AddTo(ws.sumB[l], ws.sumB[l], ws.deltas[l])
+		matrix.AddOuterTo(ws.sumW[l], ws.sumW[l], 1, ws.deltaVecs[l], ws.activationVec(l))
+	}
+}
+
+// fits tells whether the workspace was built for the passed layer sizes.
+func (ws *workspace) fits(sizes []int) bool {
+	if len(ws.activations) != len(sizes)-1 || ws.input.RawMatrix().Cols != sizes[0] {
+		return false
+	}
+	for l, a := range ws.activations {
+		if a.RawMatrix().Cols != sizes[l+1] {
+			return false
+		}
+	}
+	return true
+}
+
+// forward feeds the input 'x' to the network, keeping the weighted inputs and activations of each layer.
+// If 'mask' isn't nil, it fills the dropout mask of the layer of index 'l' and returns `true` if the layer is concerned.
+func (ws *workspace) forward(weights, biases []mat.Matrix, x *Input, mask func(l int, dst *mat.Dense) bool) {
+	ws.input.SetRow(0, x.Data)
+	for l, w := range weights {
+		// z = w·a, written as a row
+		matrix.MulVecTo(ws.zVecs[l], w, ws.activationVec(l))
+		matrix.AddTo(ws.zs[l], ws.zs[l], biases[l])
+		matrix.ApplyTo(ws.activations[l], activation.Sigmoid, ws.zs[l])
+		if l < len(ws.masks) {
+			ws.masked[l] = mask != nil && mask(l, ws.masks[l])
+			if ws.masked[l] {
+				matrix.MultiplyTo(ws.activations[l], ws.activations[l], ws.masks[l])
+			}
+		}
+	}
+}
+
+// reset zeroes the sums of the gradients before a new mini-batch.
+func (ws *workspace) reset() {
+	for l := range ws.sumB {
+		ws.sumB[l].Zero()
+		ws.sumW[l].Zero()
+	}
+}
+
+//--- FUNCTIONS
+
+// newWorkspace allocates the buffers for a network of the passed layer sizes.
+func newWorkspace(sizes []int) *workspace {
+	layers := len(sizes) - 1
+	ws := &workspace{
+		input:       mat.NewDense(1, sizes[0], nil),
+		zs:          make([]*mat.Dense, layers),
+		activations: make([]*mat.Dense, layers),
+		masks:       make([]*mat.Dense, layers-1),
+		masked:      make([]bool, layers-1),
+		sps:         make([]*mat.Dense, layers-1),
+		deltas:      make([]*mat.Dense, layers),
+		zVecs:       make([]*mat.VecDense, layers),
+		actVecs:     make([]*mat.VecDense, layers),
+		deltaVecs:   make([]*mat.VecDense, layers),
+		sumB:        make([]*mat.Dense, layers),
+		sumW:        make([]*mat.Dense, layers),
+	}
+	ws.inputVec = mat.NewVecDense(sizes[0], ws.input.RawMatrix().Data)
+	for l := 0; l < layers; l++ {
+		in, out := sizes[l], sizes[l+1]
+		ws.zs[l] = mat.NewDense(1, out, nil)
+		ws.zVecs[l] = mat.NewVecDense(out, ws.zs[l].RawMatrix().Data)
+		ws.activations[l] = mat.NewDense(1, out, nil)
+		ws.actVecs[l] = mat.NewVecDense(out, ws.activations[l].RawMatrix().Data)
+		ws.deltas[l] = mat.NewDense(1, out, nil)
+		ws.deltaVecs[l] = mat.NewVecDense(out, ws.deltas[l].RawMatrix().Data)
+		ws.sumB[l] = mat.NewDense(1, out, nil)
+		ws.sumW[l] = mat.NewDense(out, in, nil)
+		if l < layers-1 {
+			ws.sps[l] = mat.NewDense(1, out, nil)
+			ws.masks[l] = mat.NewDense(1, out, nil)
+		}
+	}
+	return ws
+}
+
+// descend applies the gradient descent step `p = p - k·∑∇p` in place to each parameter, replacing the ones that aren't
+// a *mat.Dense by a copy.
+func descend(params []mat.Matrix, sums []*mat.Dense, k float64) {
+	for i, p := range params {
+		d, ok := p.(*mat.Dense)
+		if !ok {
+			d = mat.DenseCopyOf(p)
+			params[i] = d
+		}
+		matrix.AddScaledTo(d, d, -k, sums[i])
+	}
+}
+
+// penalize applies the weight penalty of the regularizer to the weights 'w', in place if they are a *mat.Dense.
+func penalize(r regularization.Regularizer, w mat.Matrix, eta, lambda float64, n int) mat.Matrix {
+	d, ok := w.(*mat.Dense)
+	if !ok || lambda == 0 {
+		return r.Update(w, eta, lambda, n)
+	}
+	r.UpdateTo(d, d, eta, lambda, n)
+	return d
+}
+
+// update replaces each value 'v' of the matrix by `fn(v)`, without the temporary copy Dense.Apply() makes of its receiver.
+func update(d *mat.Dense, fn func(v float64) float64) {
+	raw := d.RawMatrix()
+	for i := 0; i < raw.Rows; i++ {
+		row := raw.Data[i*raw.Stride : i*raw.Stride+raw.Cols]
+		for j, v := range row {
+			row[j] = fn(v)
+		}
+	}
+}
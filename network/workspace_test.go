@@ -0,0 +1,75 @@
+package network_test
+
+import (
+	"math"
+	"neuraldeep/cost"
+	"neuraldeep/network"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// learner is the part of both networks the in-place update is checked on.
+type learner interface {
+	Backprop(x *network.Input) (biasesByLayer, weightsByLayer []mat.Matrix)
+	Parameters() (biases, weights []mat.Matrix)
+}
+
+// TestUpdateMiniBatch ...
+func TestUpdateMiniBatch(t *testing.T) {
+	data := randomDataset(20, 8, 3)
+	net1, err := network.Init([]int{8, 6, 5, 3})
+	assert.NilError(t, err)
+	for _, name := range []string{cost.CROSS_ENTROPY, cost.QUADRATIC_COST} {
+		net2, _ := twins(t, []int{8, 6, 5, 3}, name)
+		// Twice, to reuse the buffers
+		for i := 0; i < 2; i++ {
+			batch := data[10*i : 10*(i+1)]
+			expected := descent(net2, batch, 0.5)
+			net2.UpdateMiniBatch(batch, 0.5, 0, len(data))
+			assertParameters(t, net2, expected)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		batch := data[10*i : 10*(i+1)]
+		expected := descent(net1, batch, 0.5)
+		net1.UpdateMiniBatch(batch, 0.5)
+		assertParameters(t, net1, expected)
+	}
+}
+
+// descent returns the parameters after a gradient descent step computed with Backprop(), biases first then weights.
+func descent(net learner, batch network.Dataset, eta float64) (expected []mat.Matrix) {
+	biases, weights := net.Parameters()
+	sums := make([]*mat.Dense, len(biases)+len(weights))
+	for _, input := range batch {
+		nablaB, nablaW := net.Backprop(input)
+		for l, nabla := range append(nablaB, nablaW...) {
+			if sums[l] == nil {
+				sums[l] = mat.DenseCopyOf(nabla)
+			} else {
+				sums[l].Add(sums[l], nabla)
+			}
+		}
+	}
+	for l, p := range append(append([]mat.Matrix(nil), biases...), weights...) {
+		var e mat.Dense
+		e.Scale(-eta/float64(len(batch)), sums[l])
+		e.Add(p, &e)
+		expected = append(expected, &e)
+	}
+	return
+}
+
+func assertParameters(t *testing.T, net learner, expected []mat.Matrix) {
+	biases, weights := net.Parameters()
+	for l, p := range append(append([]mat.Matrix(nil), biases...), weights...) {
+		r, c := p.Dims()
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				assert.Assert(t, math.Abs(p.At(i, j)-expected[l].At(i, j)) < 1e-12)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package preprocess
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	PCA_WHITENING = "pcaWhitening"
+
+	DEFAULT_WHITENING_EPSILON = 1e-5
+
+	fitBatchSize = 1_000
+)
+
+//--- TYPES
+
+// PCAWhitening projects the centered inputs onto the principal components of the training data,
+// each component being divided by the square root of its variance (plus 'Epsilon' to avoid amplifying noise),
+// so that the transformed features are uncorrelated and of unit variance.
+// If 'Components' is set before fitting, only that many leading components are kept, reducing the size of the input layer accordingly.
+type PCAWhitening struct {
+	Components int         `json:"components"`
+	Epsilon    float64     `json:"epsilon"`
+	Mean       []float64   `json:"mean"`
+	Whitening  [][]float64 `json:"whitening"`
+}
+
+//--- METHODS
+
+// Fit ...
+func (p *PCAWhitening) Fit(data [][]float64) error {
+	d, err := checkDimensions(data)
+	if err != nil {
+		return err
+	}
+	if p.Components < 0 || p.Components > d {
+		return errors.New("invalid number of components")
+	}
+	k := p.Components
+	if k == 0 {
+		k = d
+	}
+	n := float64(len(data))
+	p.Mean = make([]float64, d)
+	for _, x := range data {
+		for j, v := range x {
+			p.Mean[j] += v / n
+		}
+	}
+
+	// Accumulate the covariance matrix by batches of centered inputs to benefit from BLAS
+	cov := mat.NewSymDense(d, nil)
+	for start := 0; start < len(data); start += fitBatchSize {
+		end := start + fitBatchSize
+		if end > len(data) {
+			end = len(data)
+		}
+		batch := mat.NewDense(end-start, d, nil)
+		for i, x := range data[start:end] {
+			for j, v := range x {
+				batch.Set(i, j, v-p.Mean[j])
+			}
+		}
+		cov.SymRankK(cov, 1/n, batch.T())
+	}
+
+	var eigen mat.EigenSym
+	if ok := eigen.Factorize(cov, true); !ok {
+		return errors.New("eigen decomposition failed")
+	}
+	values := eigen.Values(nil)
+	var vectors mat.Dense
+	eigen.VectorsTo(&vectors)
+
+	// Eigenvalues are in ascending order: keep the last k ones, largest first
+	p.Whitening = make([][]float64, k)
+	for c := 0; c < k; c++ {
+		col := d - 1 - c
+		scale := 1 / math.Sqrt(math.Max(values[col], 0)+p.Epsilon)
+		row := make([]float64, d)
+		for j := 0; j < d; j++ {
+			row[j] = vectors.At(j, col) * scale
+		}
+		p.Whitening[c] = row
+	}
+	return nil
+}
+
+// Transform ...
+func (p *PCAWhitening) Transform(x []float64) []float64 {
+	centered := make([]float64, len(x))
+	for j, v := range x {
+		centered[j] = v - p.Mean[j]
+	}
+	y := make([]float64, len(p.Whitening))
+	for c, row := range p.Whitening {
+		for j, w := range row {
+			y[c] += w * centered[j]
+		}
+	}
+	return y
+}
+
+// GetName ...
+func (p *PCAWhitening) GetName() string {
+	return PCA_WHITENING
+}
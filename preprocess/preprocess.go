@@ -0,0 +1,150 @@
+package preprocess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// A package to transform the input data before it's fed to a network, eg. to keep raw pixels of 0-255 from saturating the sigmoids.
+// Transformers are fitted on the training data only, then applied as is to the validation, test and prediction inputs.
+
+//--- TYPES
+
+// Transformer ...
+type Transformer interface {
+	Fit(data [][]float64) error
+	Transform(x []float64) []float64
+	GetName() string
+}
+
+// Pipeline applies a list of transformers in order.
+type Pipeline []Transformer
+
+// step is the JSON representation of a fitted transformer.
+type step struct {
+	Name   string          `json:"name"`
+	Params json.RawMessage `json:"params"`
+}
+
+//--- METHODS
+
+// Fit fits each transformer in turn on the 'data' as transformed by the previous ones.
+func (p Pipeline) Fit(data [][]float64) error {
+	if len(data) == 0 {
+		return errors.New("no data to fit")
+	}
+	current := data
+	for i, t := range p {
+		if err := t.Fit(current); err != nil {
+			return err
+		}
+		if i < len(p)-1 {
+			next := make([][]float64, len(current))
+			for j, x := range current {
+				next[j] = t.Transform(x)
+			}
+			current = next
+		}
+	}
+	return nil
+}
+
+// Transform returns a transformed copy of 'x'.
+func (p Pipeline) Transform(x []float64) []float64 {
+	y := append([]float64(nil), x...)
+	for _, t := range p {
+		y = t.Transform(y)
+	}
+	return y
+}
+
+// MarshalJSON ...
+func (p Pipeline) MarshalJSON() ([]byte, error) {
+	steps := make([]step, len(p))
+	for i, t := range p {
+		params, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		steps[i] = step{
+			Name:   t.GetName(),
+			Params: params,
+		}
+	}
+	return json.Marshal(steps)
+}
+
+// UnmarshalJSON ...
+func (p *Pipeline) UnmarshalJSON(data []byte) error {
+	var steps []step
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return err
+	}
+	pipeline := make(Pipeline, len(steps))
+	for i, s := range steps {
+		t, err := New(s.Name)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(s.Params, t); err != nil {
+			return fmt.Errorf("invalid %s parameters: %w", s.Name, err)
+		}
+		pipeline[i] = t
+	}
+	*p = pipeline
+	return nil
+}
+
+//--- FUNCTIONS
+
+// New returns an unfitted transformer.
+func New(name string) (Transformer, error) {
+	switch name {
+	case MIN_MAX:
+		return &MinMaxScaler{}, nil
+	case Z_SCORE:
+		return &StandardScaler{}, nil
+	case PCA_WHITENING:
+		return &PCAWhitening{Epsilon: DEFAULT_WHITENING_EPSILON}, nil
+	default:
+		return nil, errors.New("unavailable preprocessing")
+	}
+}
+
+// NewPipeline returns the unfitted pipeline of the transformers of the passed names.
+// The optional 'epsilon' replaces the DEFAULT_WHITENING_EPSILON of the PCA whitening: the larger it is, the less the
+// low-variance components, mostly noise, are amplified.
+func NewPipeline(names []string, epsilon ...float64) (p Pipeline, err error) {
+	if len(epsilon) > 0 && epsilon[0] < 0 {
+		err = errors.New("invalid whitening epsilon")
+		return
+	}
+	for _, name := range names {
+		t, e := New(name)
+		if e != nil {
+			err = e
+			return
+		}
+		if pca, ok := t.(*PCAWhitening); ok && len(epsilon) > 0 {
+			pca.Epsilon = epsilon[0]
+		}
+		p = append(p, t)
+	}
+	return
+}
+
+// utility functions
+
+func checkDimensions(data [][]float64) (int, error) {
+	if len(data) == 0 {
+		return 0, errors.New("no data to fit")
+	}
+	d := len(data[0])
+	for _, x := range data {
+		if len(x) != d {
+			return 0, errors.New("inconsistent input sizes")
+		}
+	}
+	return d, nil
+}
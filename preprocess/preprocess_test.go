@@ -0,0 +1,116 @@
+package preprocess_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"neuraldeep/preprocess"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+var data = [][]float64{
+	{0, 10, 5},
+	{255, 20, 5},
+	{128, 30, 5},
+}
+
+// TestMinMax ...
+func TestMinMax(t *testing.T) {
+	s, _ := preprocess.New(preprocess.MIN_MAX)
+	if err := s.Fit(data); err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, s.Transform([]float64{255, 15, 7}), []float64{1, 0.25, 0})
+}
+
+// TestZScore ...
+func TestZScore(t *testing.T) {
+	s, _ := preprocess.New(preprocess.Z_SCORE)
+	if err := s.Fit(data); err != nil {
+		t.Fatal(err)
+	}
+	y := s.Transform([]float64{128, 30, 7})
+	assert.Equal(t, y[1], math.Sqrt(1.5))
+	assert.Equal(t, y[2], 2.)
+}
+
+// TestPCAWhitening ...
+func TestPCAWhitening(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var correlated [][]float64
+	for i := 0; i < 2000; i++ {
+		a, b := r.NormFloat64(), r.NormFloat64()
+		correlated = append(correlated, []float64{3*a + 1, a + 0.5*b, -2 * b})
+	}
+	p, err := preprocess.NewPipeline([]string{preprocess.PCA_WHITENING})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Fit(correlated); err != nil {
+		t.Fatal(err)
+	}
+	// The whitened features are uncorrelated with unit variance, except the null third component
+	cov := make([][]float64, 3)
+	for j := range cov {
+		cov[j] = make([]float64, 3)
+	}
+	for _, x := range correlated {
+		y := p.Transform(x)
+		for i := range y {
+			for j := range y {
+				cov[i][j] += y[i] * y[j] / float64(len(correlated))
+			}
+		}
+	}
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			expected := 0.
+			if i == j {
+				expected = 1.
+			}
+			assert.Assert(t, math.Abs(cov[i][j]-expected) < 1e-3, "cov[%d][%d] = %f", i, j, cov[i][j])
+		}
+	}
+}
+
+// TestWhiteningEpsilon ...
+func TestWhiteningEpsilon(t *testing.T) {
+	p, err := preprocess.NewPipeline([]string{preprocess.MIN_MAX, preprocess.PCA_WHITENING}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, p[1].(*preprocess.PCAWhitening).Epsilon, 0.1)
+
+	p, _ = preprocess.NewPipeline([]string{preprocess.PCA_WHITENING})
+	assert.Equal(t, p[0].(*preprocess.PCAWhitening).Epsilon, preprocess.DEFAULT_WHITENING_EPSILON)
+
+	_, err = preprocess.NewPipeline([]string{preprocess.PCA_WHITENING}, -1)
+	assert.Error(t, err, "invalid whitening epsilon")
+}
+
+// TestPipelineJSON ...
+func TestPipelineJSON(t *testing.T) {
+	p, err := preprocess.NewPipeline([]string{preprocess.MIN_MAX, preprocess.Z_SCORE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Fit(data); err != nil {
+		t.Fatal(err)
+	}
+	bytes, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded preprocess.Pipeline
+	if err = json.Unmarshal(bytes, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	x := []float64{100, 12, 5}
+	assert.DeepEqual(t, loaded.Transform(x), p.Transform(x))
+	assert.DeepEqual(t, x, []float64{100, 12, 5})
+
+	_, err = preprocess.NewPipeline([]string{"log"})
+	assert.Error(t, err, "unavailable preprocessing")
+}
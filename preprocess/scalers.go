@@ -0,0 +1,103 @@
+package preprocess
+
+import (
+	"math"
+)
+
+const (
+	MIN_MAX = "minMax"
+	Z_SCORE = "zScore"
+)
+
+//--- TYPES
+
+// MinMaxScaler rescales each feature to the [0, 1] range observed on the training data.
+// A feature that is constant in the training data is mapped to 0.
+type MinMaxScaler struct {
+	Min []float64 `json:"min"`
+	Max []float64 `json:"max"`
+}
+
+// StandardScaler standardizes each feature to a zero mean and unit variance, ie. the z-score `(x - μ) / σ`.
+// A feature that is constant in the training data is only centered.
+type StandardScaler struct {
+	Mean   []float64 `json:"mean"`
+	StdDev []float64 `json:"stdDev"`
+}
+
+//--- METHODS
+
+// Fit ...
+func (s *MinMaxScaler) Fit(data [][]float64) error {
+	d, err := checkDimensions(data)
+	if err != nil {
+		return err
+	}
+	s.Min = append([]float64(nil), data[0]...)
+	s.Max = append([]float64(nil), data[0]...)
+	for _, x := range data[1:] {
+		for j := 0; j < d; j++ {
+			s.Min[j] = math.Min(s.Min[j], x[j])
+			s.Max[j] = math.Max(s.Max[j], x[j])
+		}
+	}
+	return nil
+}
+
+// Transform ...
+func (s *MinMaxScaler) Transform(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for j, v := range x {
+		if r := s.Max[j] - s.Min[j]; r > 0 {
+			y[j] = (v - s.Min[j]) / r
+		}
+	}
+	return y
+}
+
+// GetName ...
+func (s *MinMaxScaler) GetName() string {
+	return MIN_MAX
+}
+
+// Fit ...
+func (s *StandardScaler) Fit(data [][]float64) error {
+	d, err := checkDimensions(data)
+	if err != nil {
+		return err
+	}
+	n := float64(len(data))
+	s.Mean = make([]float64, d)
+	s.StdDev = make([]float64, d)
+	for _, x := range data {
+		for j, v := range x {
+			s.Mean[j] += v / n
+		}
+	}
+	for _, x := range data {
+		for j, v := range x {
+			s.StdDev[j] += (v - s.Mean[j]) * (v - s.Mean[j]) / n
+		}
+	}
+	for j := range s.StdDev {
+		s.StdDev[j] = math.Sqrt(s.StdDev[j])
+	}
+	return nil
+}
+
+// Transform ...
+func (s *StandardScaler) Transform(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for j, v := range x {
+		y[j] = v - s.Mean[j]
+		if s.StdDev[j] > 0 {
+			y[j] /= s.StdDev[j]
+		}
+	}
+	return y
+}
+
+// GetName ...
+func (s *StandardScaler) GetName() string {
+	return Z_SCORE
+}
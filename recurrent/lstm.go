@@ -0,0 +1,158 @@
+package recurrent
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/activation"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const LSTM_CELL = "lstm"
+
+//--- TYPES
+
+// LSTM is a long short-term memory layer (Hochreiter & Schmidhuber, 1997), whose cell state `c` is updated through gates
+// rather than rewritten at each step, which lets the gradients flow over many more steps than through an RNN:
+//
+//	i, f, o = σ(zᵢ), σ(z_f), σ(zₒ)   g = tanh(z_g)   where z = xₜ·Wxᵀ + hₜ₋₁·Whᵀ + b
+//	cₜ = f ⊙ cₜ₋₁ + i ⊙ g             hₜ = o ⊙ tanh(cₜ)
+//
+// The weights and biases of the four gates are stacked in this order, ie. 'Wx' is 4·hidden×in.
+type LSTM struct {
+	Wx      *mat.Dense // 4·hidden×in
+	Wh      *mat.Dense // 4·hidden×hidden
+	B       *mat.Dense // 1×4·hidden
+	nablaWx *mat.Dense
+	nablaWh *mat.Dense
+	nablaB  *mat.Dense
+	h, c    *mat.Dense
+	cache   []lstmStep
+}
+
+// lstmStep holds the values of a forward step needed by the backward pass, the gates being m×hidden matrices.
+type lstmStep struct {
+	x                   mat.Matrix
+	hPrev, cPrev        *mat.Dense
+	i, f, o, g, c, tanh *mat.Dense
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *LSTM) Backward(grads []mat.Matrix) []mat.Matrix {
+	l.nablaWx.Zero()
+	l.nablaWh.Zero()
+	l.nablaB.Zero()
+	m, hidden := l.h.Dims()
+	dxs := make([]mat.Matrix, len(grads))
+	dhNext, dcNext := mat.NewDense(m, hidden, nil), mat.NewDense(m, hidden, nil)
+	for t := len(grads) - 1; t >= 0; t-- {
+		s := l.cache[t]
+		dz := mat.NewDense(m, 4*hidden, nil)
+		for r := 0; r < m; r++ {
+			for j := 0; j < hidden; j++ {
+				i, f, o, g := s.i.At(r, j), s.f.At(r, j), s.o.At(r, j), s.g.At(r, j)
+				tanh := s.tanh.At(r, j)
+				dh := grads[t].At(r, j) + dhNext.At(r, j)
+				dc := dcNext.At(r, j) + dh*o*(1-tanh*tanh)
+				dz.Set(r, j, dc*g*i*(1-i))
+				dz.Set(r, hidden+j, dc*s.cPrev.At(r, j)*f*(1-f))
+				dz.Set(r, 2*hidden+j, dh*tanh*o*(1-o))
+				dz.Set(r, 3*hidden+j, dc*i*(1-g*g))
+				dcNext.Set(r, j, dc*f)
+			}
+		}
+		addProduct(l.nablaWx, dz.T(), s.x)
+		addProduct(l.nablaWh, dz.T(), s.hPrev)
+		l.nablaB.Add(l.nablaB, matrix.ColumnSums(dz))
+		dxs[t] = matrix.Dot(dz, l.Wx)
+		dhNext.Mul(dz, l.Wh)
+	}
+	return dxs
+}
+
+// Forward ...
+func (l *LSTM) Forward(xs []mat.Matrix) []mat.Matrix {
+	m, _ := xs[0].Dims()
+	if r, _ := l.h.Dims(); r != m {
+		l.Reset(m)
+	}
+	hidden := l.Wh.RawMatrix().Cols
+	l.cache = make([]lstmStep, len(xs))
+	outputs := make([]mat.Matrix, len(xs))
+	for t, x := range xs {
+		z := mat.NewDense(m, 4*hidden, nil)
+		z.Mul(x, l.Wx.T())
+		addProduct(z, l.h, l.Wh.T())
+		s := lstmStep{x: x, hPrev: l.h, cPrev: l.c}
+		gates := make([]*mat.Dense, 4)
+		for k := range gates {
+			gates[k] = mat.NewDense(m, hidden, nil)
+			gates[k].Apply(func(_, j int, v float64) float64 {
+				v += l.B.At(0, k*hidden+j)
+				if k == 3 {
+					return math.Tanh(v)
+				}
+				return activation.Sigmoid(0, j, v)
+			}, z.Slice(0, m, k*hidden, (k+1)*hidden))
+		}
+		s.i, s.f, s.o, s.g = gates[0], gates[1], gates[2], gates[3]
+		s.c, s.tanh = mat.NewDense(m, hidden, nil), mat.NewDense(m, hidden, nil)
+		s.c.Apply(func(r, j int, _ float64) float64 {
+			return s.f.At(r, j)*s.cPrev.At(r, j) + s.i.At(r, j)*s.g.At(r, j)
+		}, s.c)
+		s.tanh.Apply(func(_, _ int, v float64) float64 {
+			return math.Tanh(v)
+		}, s.c)
+		h := mat.NewDense(m, hidden, nil)
+		h.MulElem(s.o, s.tanh)
+		l.h, l.c = h, s.c
+		l.cache[t] = s
+		outputs[t] = h
+	}
+	return outputs
+}
+
+// GetName ...
+func (l *LSTM) GetName() string {
+	return LSTM_CELL
+}
+
+// Grads returns the gradients of the input weights, the recurrent weights and the biases.
+func (l *LSTM) Grads() []*mat.Dense {
+	return []*mat.Dense{l.nablaWx, l.nablaWh, l.nablaB}
+}
+
+// Params returns the input weights, the recurrent weights and the biases.
+func (l *LSTM) Params() []*mat.Dense {
+	return []*mat.Dense{l.Wx, l.Wh, l.B}
+}
+
+// Reset sets zero hidden and cell states for a mini-batch of 'batchSize' sequences.
+func (l *LSTM) Reset(batchSize int) {
+	hidden := l.Wh.RawMatrix().Cols
+	l.h, l.c = mat.NewDense(batchSize, hidden, nil), mat.NewDense(batchSize, hidden, nil)
+}
+
+//--- FUNCTIONS
+
+// NewLSTM returns a long short-term memory layer of 'hidden' neurons fed by 'in' inputs, initialized as an RNN
+// except for the biases of the forget gate, set to 1 so that the cell state is kept by default (Jozefowicz et al., 2015).
+// If a random generator is passed, the weights are drawn from it instead of the global one.
+func NewLSTM(in, hidden int, rng ...*rand.Rand) *LSTM {
+	l := &LSTM{
+		Wx:      mat.DenseCopyOf(matrix.Gaussian(4*hidden, in, 0, 1/math.Sqrt(float64(in)), rng...)),
+		Wh:      mat.DenseCopyOf(matrix.Gaussian(4*hidden, hidden, 0, 1/math.Sqrt(float64(hidden)), rng...)),
+		B:       mat.NewDense(1, 4*hidden, nil),
+		nablaWx: mat.NewDense(4*hidden, in, nil),
+		nablaWh: mat.NewDense(4*hidden, hidden, nil),
+		nablaB:  mat.NewDense(1, 4*hidden, nil),
+	}
+	for j := hidden; j < 2*hidden; j++ {
+		l.B.Set(0, j, 1)
+	}
+	l.Reset(1)
+	return l
+}
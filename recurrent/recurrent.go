@@ -0,0 +1,215 @@
+package recurrent
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"neuraldeep/layer"
+	"neuraldeep/network"
+	"neuraldeep/utils/matrix"
+	"neuraldeep/utils/python"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// A package of recurrent networks, whose hidden state carries the information of the previous steps of a sequence.
+// The sequences of a mini-batch are fed step by step, each step being a matrix whose rows are the inputs of the sequences
+// at that step, and the gradients are computed by truncated backpropagation through time: the sequences are processed
+// in chunks of a few steps, the hidden state flowing from one chunk to the next but the gradients stopping at their boundary.
+
+const (
+	DEFAULT_TRUNCATION = 25
+	DEFAULT_CLIP       = 5.
+)
+
+//--- TYPES
+
+// Layer is a recurrent layer processing a chunk of steps of the sequences of a mini-batch.
+// Forward() starts from the hidden state left by the previous call, or from a zero state after Reset(), and keeps what
+// Backward() needs to propagate the gradients of the cost with respect to the outputs of each step back through the chunk.
+// Params() and Grads() are those of layer.Layer, the gradients being summed over the steps and the sequences.
+type Layer interface {
+	Forward(xs []mat.Matrix) []mat.Matrix
+	Backward(grads []mat.Matrix) []mat.Matrix
+	Params() []*mat.Dense
+	Grads() []*mat.Dense
+	Reset(batchSize int)
+	GetName() string
+}
+
+// Network is a recurrent layer followed by a feedforward model applied to its output at each step, eg. a dense layer
+// and a softmax, the loss of which is the one of the network.
+type Network struct {
+	Recurrent  Layer
+	Output     *layer.Sequential
+	Truncation int        // the number of steps of the truncated backpropagation through time, 0 for whole sequences
+	Clip       float64    // the maximum norm of the mean gradients of all the parameters, to avoid their explosion, 0 to disable it
+	Rand       *rand.Rand // the generator shuffling the training data in SGD(), the global one if nil
+}
+
+//--- METHODS
+
+// Reset clears the hidden state before a new sequence is fed to Step().
+func (net *Network) Reset() {
+	net.Recurrent.Reset(1)
+}
+
+// SGD trains the network using mini-batch stochastic gradient descent, the sequences of a mini-batch being processed
+// together. Sequences of different lengths are grouped by length into mini-batches, which are fed in a random order.
+// If an 'evaluation' dataset is passed, its mean cost per step is printed after each epoch.
+func (net *Network) SGD(training network.SequenceDataset, epochs, miniBatchSize int, eta float64, evaluation ...network.SequenceDataset) (trainingCost []float64) {
+	for j := 0; j < epochs; j++ {
+		shuffle := rand.Shuffle
+		if net.Rand != nil {
+			training.Shuffle(net.Rand)
+			shuffle = net.Rand.Shuffle
+		} else {
+			training.Shuffle()
+		}
+		miniBatches := training.MiniBatchesByLength(miniBatchSize)
+		shuffle(len(miniBatches), func(a, b int) {
+			miniBatches[a], miniBatches[b] = miniBatches[b], miniBatches[a]
+		})
+		cost := 0.
+		for _, miniBatch := range miniBatches {
+			cost += net.UpdateMiniBatch(miniBatch, eta) * float64(miniBatch.Steps())
+		}
+		if steps := training.Steps(); steps > 0 {
+			cost /= float64(steps)
+		}
+		trainingCost = append(trainingCost, cost)
+		if len(evaluation) > 0 {
+			fmt.Printf("epoch %d: training cost %f, evaluation cost %f\n", j+1, trainingCost[j], net.TotalCost(evaluation[0]))
+		} else {
+			fmt.Printf("epoch %d: training cost %f\n", j+1, trainingCost[j])
+		}
+	}
+	return
+}
+
+// Step feeds a single input following the previous ones since Reset(), and returns the 1×n output of the network.
+func (net *Network) Step(x mat.Vector) mat.Matrix {
+	return net.Output.Forward(net.Recurrent.Forward([]mat.Matrix{x.T()})[0], false)
+}
+
+// TotalCost returns the mean cost per step of the network over the dataset, each sequence being fed from a zero state.
+func (net *Network) TotalCost(data network.SequenceDataset) float64 {
+	cost := 0.
+	for _, s := range data {
+		if len(s) == 0 {
+			continue
+		}
+		net.Recurrent.Reset(1)
+		xs, ys := steps(network.SequenceDataset{s}, 0, len(s))
+		cost += net.Output.Loss.Function(net.Output.Forward(stack(net.Recurrent.Forward(xs)), false), stack(ys))
+	}
+	if data.Steps() == 0 {
+		return 0
+	}
+	return cost / float64(data.Steps())
+}
+
+// UpdateMiniBatch trains the network on the sequences of the mini-batch, which must be of the same length (see
+// SequenceDataset.MiniBatchesByLength()), at the learning rate 'eta'. The parameters are updated after each chunk
+// of `net.Truncation` steps, and the mean cost per step is returned, 0 for empty sequences.
+func (net *Network) UpdateMiniBatch(miniBatch network.SequenceDataset, eta float64) float64 {
+	length := len(miniBatch[0])
+	for _, s := range miniBatch {
+		if len(s) != length {
+			panic(fmt.Errorf("sequences of different lengths in the mini-batch: %d and %d", length, len(s)))
+		}
+	}
+	if length == 0 {
+		return 0
+	}
+	truncation := net.Truncation
+	if truncation <= 0 {
+		truncation = length
+	}
+	net.Recurrent.Reset(len(miniBatch))
+	params := append(net.Recurrent.Params(), net.Output.Params()...)
+	cost := 0.
+	chunks := python.XRange(0, length, truncation)
+	for k := range chunks.Len() {
+		start := chunks.At(k)
+		xs, ys := steps(miniBatch, start, min(start+truncation, length))
+		output := net.Output.Forward(stack(net.Recurrent.Forward(xs)), true)
+		y := stack(ys)
+		cost += net.Output.Loss.Function(output, y)
+		net.Recurrent.Backward(split(net.Output.BackwardLoss(output, y), len(xs)))
+		grads := append(net.Recurrent.Grads(), net.Output.Grads()...)
+		// The gradients are summed over the sequences: their mean is rescaled if its norm is too large
+		scale := 1 / float64(len(miniBatch))
+		if net.Clip > 0 {
+			if size := scale * norm(grads); size > net.Clip {
+				scale *= net.Clip / size
+			}
+		}
+		for i, p := range params {
+			matrix.AddScaledTo(p, p, -eta*scale, grads[i])
+		}
+	}
+	return cost / float64(len(miniBatch)*length)
+}
+
+//--- FUNCTIONS
+
+// New returns a network feeding the outputs of the recurrent layer to the 'output' model, with the default truncation
+// and gradient clipping.
+func New(recurrent Layer, output *layer.Sequential) *Network {
+	return &Network{
+		Recurrent:  recurrent,
+		Output:     output,
+		Truncation: DEFAULT_TRUNCATION,
+		Clip:       DEFAULT_CLIP,
+	}
+}
+
+// norm returns the Euclidean norm of all the values of the passed matrices.
+func norm(ms []*mat.Dense) float64 {
+	sum := 0.
+	for _, m := range ms {
+		sum += math.Pow(mat.Norm(m, 2), 2)
+	}
+	return math.Sqrt(sum)
+}
+
+// split cuts the rows of 'm' into 'n' matrices of the same size, the inverse of stack().
+func split(m mat.Matrix, n int) []mat.Matrix {
+	r, c := m.Dims()
+	rows := r / n
+	d := mat.DenseCopyOf(m)
+	parts := make([]mat.Matrix, n)
+	for t := range parts {
+		parts[t] = d.Slice(t*rows, (t+1)*rows, 0, c)
+	}
+	return parts
+}
+
+// stack returns the rows of the passed matrices, one after the other, so that a feedforward model processes all the steps
+// at once.
+func stack(ms []mat.Matrix) *mat.Dense {
+	r, c := ms[0].Dims()
+	s := mat.NewDense(r*len(ms), c, nil)
+	for t, m := range ms {
+		s.Slice(t*r, (t+1)*r, 0, c).(*mat.Dense).Copy(m)
+	}
+	return s
+}
+
+// steps returns the inputs and the desired outputs of the steps from 'start' included to 'end' excluded of the sequences,
+// each step being a matrix with one row per sequence.
+func steps(data network.SequenceDataset, start, end int) (xs, ys []mat.Matrix) {
+	for t := start; t < end; t++ {
+		x := mat.NewDense(len(data), len(data[0][t].Data), nil)
+		y := mat.NewDense(len(data), data[0][t].Label.Vector.Len(), nil)
+		for i, s := range data {
+			x.SetRow(i, s[t].Data)
+			for j := 0; j < s[t].Label.Vector.Len(); j++ {
+				y.Set(i, j, s[t].Label.Vector.AtVec(j))
+			}
+		}
+		xs, ys = append(xs, x), append(ys, y)
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package recurrent_test
+
+import (
+	"math/rand"
+	"neuraldeep/gradcheck"
+	"neuraldeep/layer"
+	"neuraldeep/network"
+	"neuraldeep/recurrent"
+	"neuraldeep/utils/matrix"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestBackward compares the gradients of the recurrent layers through 4 steps with their central difference estimates,
+// for the cost `C = ∑ₜ ∑ Wₜ ⊙ hₜ` whose gradients with respect to the outputs are the random weights `Wₜ`.
+func TestBackward(t *testing.T) {
+	for _, l := range []recurrent.Layer{recurrent.NewRNN(3, 5), recurrent.NewLSTM(3, 5)} {
+		var xs, weights []mat.Matrix
+		for range 4 {
+			xs = append(xs, matrix.Gaussian(2, 3, 0, 1))
+			weights = append(weights, matrix.Gaussian(2, 5, 0, 1))
+		}
+		loss := func() (c float64) {
+			l.Reset(2)
+			for t, h := range l.Forward(xs) {
+				c += mat.Sum(matrix.Multiply(h, weights[t]))
+			}
+			return
+		}
+		loss()
+		dxs := l.Backward(weights)
+		grads := l.Grads()
+		for i, p := range l.Params() {
+			assert.NilError(t, gradcheck.CompareMatrix(p, grads[i], loss), l.GetName())
+		}
+		for t0, x := range xs {
+			assert.NilError(t, gradcheck.CompareMatrix(x.(*mat.Dense), dxs[t0], loss), l.GetName())
+		}
+	}
+}
+
+// TestForward checks that the hidden state flows from one chunk of steps to the next.
+func TestForward(t *testing.T) {
+	for _, l := range []recurrent.Layer{recurrent.NewRNN(3, 4), recurrent.NewLSTM(3, 4)} {
+		var xs []mat.Matrix
+		for range 5 {
+			xs = append(xs, matrix.Gaussian(2, 3, 0, 1))
+		}
+		l.Reset(2)
+		whole := l.Forward(xs)
+		l.Reset(2)
+		chunks := append(l.Forward(xs[:2]), l.Forward(xs[2:])...)
+		for i := range whole {
+			assert.Assert(t, mat.EqualApprox(whole[i], chunks[i], 1e-12))
+		}
+		// The same input gives another output once there is a state, until Reset()
+		assert.Assert(t, !mat.EqualApprox(l.Forward(xs[:1])[0], whole[0], 1e-12))
+		l.Reset(2)
+		assert.Assert(t, mat.EqualApprox(l.Forward(xs[:1])[0], whole[0], 1e-12))
+	}
+}
+
+// TestNetwork trains a character-level language model on a repeated sentence.
+func TestNetwork(t *testing.T) {
+	text := strings.Repeat("hello world. ", 40)
+	vocab := recurrent.NewVocabulary(text)
+	assert.Equal(t, string(vocab.Runes), " .dehlorw")
+	data := vocab.Sequences(text, 4)
+	assert.Equal(t, len(data), 4)
+	assert.Equal(t, len(data[0]), (len(text)-1)/4)
+	// The label of each character is the next one
+	i, _ := vocab.Index('e')
+	assert.Equal(t, data[0][0].Data[i], 0.)
+	assert.Equal(t, int(data[0][0].Label.Value), i)
+
+	for _, cell := range []recurrent.Layer{recurrent.NewRNN(vocab.Size(), 20), recurrent.NewLSTM(vocab.Size(), 20)} {
+		net := recurrent.New(cell, layer.NewSequential(layer.LogLikelihoodLoss{}, layer.NewDense(20, vocab.Size()), &layer.Softmax{}))
+		net.Truncation = 10
+		before := net.TotalCost(data)
+		costs := net.SGD(data, 30, 4, 0.1)
+		assert.Assert(t, costs[len(costs)-1] < before/4, "%s: %f then %f", cell.GetName(), before, costs[len(costs)-1])
+		assert.Equal(t, vocab.Sample(net, "hello wor", 12, 0.1, rand.New(rand.NewSource(1))), "ld. hello wo")
+	}
+}
+
+// TestUnequalLengths trains a network on sequences of different lengths, one of them empty.
+func TestUnequalLengths(t *testing.T) {
+	text := strings.Repeat("hello world. ", 40)
+	vocab := recurrent.NewVocabulary(text)
+	data := vocab.Sequences(text, 4)
+	data[1], data[2], data[3] = data[1][:50], data[2][:0], data[3][:97]
+
+	net := recurrent.New(recurrent.NewLSTM(vocab.Size(), 20), layer.NewSequential(layer.LogLikelihoodLoss{}, layer.NewDense(20, vocab.Size()), &layer.Softmax{}))
+	net.Truncation = 10
+	before := net.TotalCost(data)
+	costs := net.SGD(data, 30, 4, 0.1)
+	assert.Assert(t, costs[len(costs)-1] < before/2, "%f then %f", before, costs[len(costs)-1])
+	assert.Equal(t, net.UpdateMiniBatch(network.SequenceDataset{{}, {}}, 0.1), 0.)
+}
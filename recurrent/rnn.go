@@ -0,0 +1,119 @@
+package recurrent
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const RNN_CELL = "rnn"
+
+//--- TYPES
+
+// RNN is a vanilla recurrent layer (Elman, 1990) computing the hidden state `hₜ = tanh(xₜ·Wxᵀ + hₜ₋₁·Whᵀ + b)` at each step.
+type RNN struct {
+	Wx      *mat.Dense // hidden×in
+	Wh      *mat.Dense // hidden×hidden
+	B       *mat.Dense // 1×hidden
+	nablaWx *mat.Dense
+	nablaWh *mat.Dense
+	nablaB  *mat.Dense
+	h       *mat.Dense
+	xs      []mat.Matrix
+	hs      []*mat.Dense // the hidden states of the chunk, starting with the one before its first step
+}
+
+//--- METHODS
+
+// Backward ...
+func (l *RNN) Backward(grads []mat.Matrix) []mat.Matrix {
+	l.nablaWx.Zero()
+	l.nablaWh.Zero()
+	l.nablaB.Zero()
+	m, hidden := l.hs[0].Dims()
+	dxs := make([]mat.Matrix, len(grads))
+	dhNext := mat.NewDense(m, hidden, nil)
+	for t := len(grads) - 1; t >= 0; t-- {
+		h := l.hs[t+1]
+		// δz = (∂C/∂hₜ + ∂C/∂hₜ₊₁ ∂hₜ₊₁/∂hₜ) ⊙ (1 - hₜ²)
+		dz := mat.NewDense(m, hidden, nil)
+		dz.Apply(func(i, j int, v float64) float64 {
+			return (v + dhNext.At(i, j)) * (1 - h.At(i, j)*h.At(i, j))
+		}, grads[t])
+		addProduct(l.nablaWx, dz.T(), l.xs[t])
+		addProduct(l.nablaWh, dz.T(), l.hs[t])
+		l.nablaB.Add(l.nablaB, matrix.ColumnSums(dz))
+		dxs[t] = matrix.Dot(dz, l.Wx)
+		dhNext.Mul(dz, l.Wh)
+	}
+	return dxs
+}
+
+// Forward ...
+func (l *RNN) Forward(xs []mat.Matrix) []mat.Matrix {
+	m, _ := xs[0].Dims()
+	if r, _ := l.h.Dims(); r != m {
+		l.Reset(m)
+	}
+	l.xs, l.hs = xs, []*mat.Dense{l.h}
+	outputs := make([]mat.Matrix, len(xs))
+	for t, x := range xs {
+		h := mat.NewDense(m, l.B.RawMatrix().Cols, nil)
+		h.Mul(x, l.Wx.T())
+		addProduct(h, l.h, l.Wh.T())
+		h.Apply(func(_, j int, v float64) float64 {
+			return math.Tanh(v + l.B.At(0, j))
+		}, h)
+		l.h = h
+		l.hs = append(l.hs, h)
+		outputs[t] = h
+	}
+	return outputs
+}
+
+// GetName ...
+func (l *RNN) GetName() string {
+	return RNN_CELL
+}
+
+// Grads returns the gradients of the input weights, the recurrent weights and the biases.
+func (l *RNN) Grads() []*mat.Dense {
+	return []*mat.Dense{l.nablaWx, l.nablaWh, l.nablaB}
+}
+
+// Params returns the input weights, the recurrent weights and the biases.
+func (l *RNN) Params() []*mat.Dense {
+	return []*mat.Dense{l.Wx, l.Wh, l.B}
+}
+
+// Reset sets a zero hidden state for a mini-batch of 'batchSize' sequences.
+func (l *RNN) Reset(batchSize int) {
+	l.h = mat.NewDense(batchSize, l.B.RawMatrix().Cols, nil)
+}
+
+//--- FUNCTIONS
+
+// NewRNN returns a recurrent layer of 'hidden' neurons fed by 'in' inputs, its weights being drawn from a Gaussian
+// distribution of standard deviation the inverse of the square root of their number of inputs, and its biases being zero.
+// If a random generator is passed, the weights are drawn from it instead of the global one.
+func NewRNN(in, hidden int, rng ...*rand.Rand) *RNN {
+	l := &RNN{
+		Wx:      mat.DenseCopyOf(matrix.Gaussian(hidden, in, 0, 1/math.Sqrt(float64(in)), rng...)),
+		Wh:      mat.DenseCopyOf(matrix.Gaussian(hidden, hidden, 0, 1/math.Sqrt(float64(hidden)), rng...)),
+		B:       mat.NewDense(1, hidden, nil),
+		nablaWx: mat.NewDense(hidden, in, nil),
+		nablaWh: mat.NewDense(hidden, hidden, nil),
+		nablaB:  mat.NewDense(1, hidden, nil),
+	}
+	l.Reset(1)
+	return l
+}
+
+// addProduct adds the matrix product `a·b` to 'dst'.
+func addProduct(dst *mat.Dense, a, b mat.Matrix) {
+	var p mat.Dense
+	p.Mul(a, b)
+	dst.Add(dst, &p)
+}
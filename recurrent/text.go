@@ -0,0 +1,126 @@
+package recurrent
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/network"
+	"slices"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+//--- TYPES
+
+// Vocabulary maps each distinct character of a text to an index, ie. to a one-hot vector, for a character-level model.
+type Vocabulary struct {
+	Runes   []rune
+	index   map[rune]int
+	inputs  [][]float64
+	targets []*network.Label
+}
+
+//--- METHODS
+
+// Index returns the index of the character 'r', and `false` if it isn't part of the vocabulary.
+func (v *Vocabulary) Index(r rune) (int, bool) {
+	i, ok := v.index[r]
+	return i, ok
+}
+
+// Input returns the one-hot vector of the character 'r', shared by all the inputs of this character.
+func (v *Vocabulary) Input(r rune) []float64 {
+	return v.inputs[v.index[r]]
+}
+
+// Sample generates 'n' characters with the network, starting with the 'seed' text. Each character is drawn from the outputs
+// of the network, which should be probabilities (eg. of a softmax layer), sharpened by a 'temperature' below 1.
+func (v *Vocabulary) Sample(net *Network, seed string, n int, temperature float64, rng *rand.Rand) string {
+	var sb strings.Builder
+	net.Reset()
+	var probs []float64
+	feed := func(r rune) {
+		output := net.Step(mat.NewVecDense(v.Size(), v.Input(r)))
+		probs = probs[:0]
+		for j := range v.Size() {
+			probs = append(probs, math.Pow(math.Max(output.At(0, j), 0), 1/temperature))
+		}
+	}
+	for _, r := range seed {
+		if _, ok := v.index[r]; ok {
+			feed(r)
+		}
+	}
+	if len(probs) == 0 {
+		feed(v.Runes[rng.Intn(v.Size())])
+	}
+	for range n {
+		sum := 0.
+		for _, p := range probs {
+			sum += p
+		}
+		draw, next := rng.Float64()*sum, len(probs)-1
+		for j, p := range probs {
+			if draw < p {
+				next = j
+				break
+			}
+			draw -= p
+		}
+		sb.WriteRune(v.Runes[next])
+		feed(v.Runes[next])
+	}
+	return sb.String()
+}
+
+// Sequences splits the text into 'n' consecutive sequences of the same length, the label of each character being the next one.
+// Training on all of them in a single mini-batch with truncated backpropagation through time lets the hidden state flow over
+// the whole text. The characters that aren't part of the vocabulary are ignored.
+func (v *Vocabulary) Sequences(text string, n int) network.SequenceDataset {
+	var runes []rune
+	for _, r := range text {
+		if _, ok := v.index[r]; ok {
+			runes = append(runes, r)
+		}
+	}
+	length := 0
+	if n > 0 && len(runes) > 1 {
+		length = (len(runes) - 1) / n
+	}
+	ds := make(network.SequenceDataset, n)
+	for i := range ds {
+		ds[i] = make(network.Sequence, length)
+		for t := range length {
+			k := i*length + t
+			ds[i][t] = &network.Input{Data: v.Input(runes[k]), Label: v.targets[v.index[runes[k+1]]]}
+		}
+	}
+	return ds
+}
+
+// Size returns the number of characters of the vocabulary.
+func (v *Vocabulary) Size() int {
+	return len(v.Runes)
+}
+
+//--- FUNCTIONS
+
+// NewVocabulary returns the vocabulary of the distinct characters of the text, sorted.
+func NewVocabulary(text string) *Vocabulary {
+	v := &Vocabulary{index: make(map[rune]int)}
+	for _, r := range text {
+		if _, ok := v.index[r]; !ok {
+			v.index[r] = 0
+			v.Runes = append(v.Runes, r)
+		}
+	}
+	slices.Sort(v.Runes)
+	for i, r := range v.Runes {
+		v.index[r] = i
+		v.targets = append(v.targets, network.ToLabel(float64(i), len(v.Runes)))
+		oneHot := make([]float64, len(v.Runes))
+		oneHot[i] = 1
+		v.inputs = append(v.inputs, oneHot)
+	}
+	return v
+}
@@ -0,0 +1,43 @@
+package regularization
+
+import (
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const ELASTIC_NET = "elasticNet"
+
+//--- TYPES
+
+// ElasticNetRegularizer combines the L1 and L2 penalties, 'Ratio' being the share of L1 in the mix.
+type ElasticNetRegularizer struct {
+	Name  string
+	Ratio float64
+}
+
+//--- METHODS
+
+// Cost returns `Ratio * λ/n ∑ |w| + (1 - Ratio) * λ/2n ∑ w²`.
+func (r ElasticNetRegularizer) Cost(weights []mat.Matrix, lambda float64, n int) float64 {
+	return r.Ratio*L1Regularizer{}.Cost(weights, lambda, n) + (1-r.Ratio)*L2Regularizer{}.Cost(weights, lambda, n)
+}
+
+// Update applies both the L1 shrinkage and the L2 weight decay, each weighted by its share.
+func (r ElasticNetRegularizer) Update(w mat.Matrix, eta, lambda float64, n int) mat.Matrix {
+	k := eta * (lambda / float64(n))
+	return matrix.Subtract(w, matrix.Add(matrix.Scale(k*r.Ratio, sign(w)), matrix.Scale(k*(1-r.Ratio), w)))
+}
+
+// UpdateTo writes the weights updated by both the L1 shrinkage and the L2 weight decay into 'dst'.
+func (r ElasticNetRegularizer) UpdateTo(dst *mat.Dense, w mat.Matrix, eta, lambda float64, n int) {
+	k := eta * (lambda / float64(n))
+	updateTo(dst, w, func(v float64) float64 {
+		return v - k*r.Ratio*signOf(v) - k*(1-r.Ratio)*v
+	})
+}
+
+// GetName ...
+func (r ElasticNetRegularizer) GetName() string {
+	return r.Name
+}
@@ -0,0 +1,69 @@
+package regularization
+
+import (
+	"math"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const L1 = "l1"
+
+//--- TYPES
+
+// L1Regularizer penalizes the sum of the absolute values of the weights.
+// Compared to L2, it shrinks the weights by a constant amount rather than proportionally,
+// so that the network tends to concentrate on a small number of high-importance connections.
+type L1Regularizer struct {
+	Name string
+}
+
+//--- METHODS
+
+// Cost returns `λ/n ∑ |w|`.
+func (r L1Regularizer) Cost(weights []mat.Matrix, lambda float64, n int) float64 {
+	sum := 0.
+	for _, w := range weights {
+		sum += mat.Sum(matrix.Apply(func(i, j int, v float64) float64 {
+			return math.Abs(v)
+		}, w))
+	}
+	return (lambda / float64(n)) * sum
+}
+
+// Update moves the weights towards zero by `ηλ/n sgn(w)`, using the convention that `sgn(0) = 0`.
+func (r L1Regularizer) Update(w mat.Matrix, eta, lambda float64, n int) mat.Matrix {
+	return matrix.Subtract(w, matrix.Scale(eta*(lambda/float64(n)), sign(w)))
+}
+
+// UpdateTo writes the weights moved towards zero by `ηλ/n sgn(w)` into 'dst'.
+func (r L1Regularizer) UpdateTo(dst *mat.Dense, w mat.Matrix, eta, lambda float64, n int) {
+	k := eta * (lambda / float64(n))
+	updateTo(dst, w, func(v float64) float64 {
+		return v - k*signOf(v)
+	})
+}
+
+// GetName ...
+func (r L1Regularizer) GetName() string {
+	return r.Name
+}
+
+// utility functions
+
+func sign(m mat.Matrix) mat.Matrix {
+	return matrix.Apply(func(i, j int, v float64) float64 {
+		return signOf(v)
+	}, m)
+}
+
+func signOf(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,46 @@
+package regularization
+
+import (
+	"math"
+	"neuraldeep/utils/matrix"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const L2 = "l2"
+
+//--- TYPES
+
+// L2Regularizer implements the weight decay, ie. the sum of the squares of all the weights.
+type L2Regularizer struct {
+	Name string
+}
+
+//--- METHODS
+
+// Cost returns `λ/2n ∑ w²`.
+func (r L2Regularizer) Cost(weights []mat.Matrix, lambda float64, n int) float64 {
+	sum := 0.
+	for _, w := range weights {
+		sum += math.Pow(mat.Norm(w, 2), 2)
+	}
+	return 0.5 * (lambda / float64(n)) * sum
+}
+
+// Update rescales the weights by the `1 - ηλ/n` factor.
+func (r L2Regularizer) Update(w mat.Matrix, eta, lambda float64, n int) mat.Matrix {
+	return matrix.Scale(1-eta*(lambda/float64(n)), w)
+}
+
+// UpdateTo writes the weights rescaled by the `1 - ηλ/n` factor into 'dst'.
+func (r L2Regularizer) UpdateTo(dst *mat.Dense, w mat.Matrix, eta, lambda float64, n int) {
+	k := 1 - eta*(lambda/float64(n))
+	updateTo(dst, w, func(v float64) float64 {
+		return k * v
+	})
+}
+
+// GetName ...
+func (r L2Regularizer) GetName() string {
+	return r.Name
+}
@@ -0,0 +1,90 @@
+package regularization_test
+
+import (
+	"fmt"
+	"neuraldeep/regularization"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestL1 ...
+func TestL1(t *testing.T) {
+	w := mat.NewDense(1, 3, []float64{0.5, -2, 0})
+	l1, err := regularization.New(regularization.L1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// r = λ/n ∑ |w|
+	assert.Equal(t, l1.Cost([]mat.Matrix{w}, 2, 10), 2./10*2.5)
+
+	updated := l1.Update(w, 0.5, 2, 10)
+	expected := []float64{0.5 - 0.1, -2 + 0.1, 0}
+	for j, e := range expected {
+		assert.Equal(t, fmt.Sprintf("%.4f", updated.At(0, j)), fmt.Sprintf("%.4f", e))
+	}
+}
+
+// TestL2 ...
+func TestL2(t *testing.T) {
+	w := mat.NewDense(1, 3, []float64{0.5, -2, 0})
+	l2, err := regularization.New(regularization.L2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// r = λ/2n ∑ w²
+	assert.Equal(t, fmt.Sprintf("%.4f", l2.Cost([]mat.Matrix{w}, 2, 10)), fmt.Sprintf("%.4f", 0.5*2./10*4.25))
+
+	updated := l2.Update(w, 0.5, 2, 10)
+	expected := []float64{0.5 * 0.9, -2 * 0.9, 0}
+	for j, e := range expected {
+		assert.Equal(t, fmt.Sprintf("%.4f", updated.At(0, j)), fmt.Sprintf("%.4f", e))
+	}
+}
+
+// TestElasticNet ...
+func TestElasticNet(t *testing.T) {
+	w := mat.NewDense(1, 3, []float64{0.5, -2, 0})
+	l1, _ := regularization.New(regularization.L1)
+	l2, _ := regularization.New(regularization.L2)
+	en, err := regularization.New(regularization.ELASTIC_NET)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weights := []mat.Matrix{w}
+	assert.Equal(t, fmt.Sprintf("%.4f", en.Cost(weights, 2, 10)), fmt.Sprintf("%.4f", 0.5*l1.Cost(weights, 2, 10)+0.5*l2.Cost(weights, 2, 10)))
+
+	updated := en.Update(w, 0.5, 2, 10)
+	expected := []float64{0.5 - 0.05 - 0.025, -2 + 0.05 + 0.1, 0}
+	for j, e := range expected {
+		assert.Equal(t, fmt.Sprintf("%.4f", updated.At(0, j)), fmt.Sprintf("%.4f", e))
+	}
+
+	_, err = regularization.New("l3")
+	assert.Error(t, err, "unavailable regularizer")
+}
+
+// TestUpdateTo ...
+func TestUpdateTo(t *testing.T) {
+	w := mat.NewDense(2, 3, []float64{0.5, -2, 0, 1.5, -0.25, 3})
+	for _, name := range []string{regularization.L1, regularization.L2, regularization.ELASTIC_NET} {
+		r, _ := regularization.New(name)
+		for _, lambda := range []float64{0.1, 2, 5} {
+			expected := r.Update(w, 0.5, lambda, 10)
+
+			// Into a new matrix, from a matrix that isn't a *mat.Dense
+			var dst mat.Dense
+			r.UpdateTo(&dst, mat.DenseCopyOf(w.T()).T(), 0.5, lambda, 10)
+			assert.Assert(t, mat.EqualApprox(&dst, expected, 1e-12), "%s with λ=%g", name, lambda)
+
+			// In place
+			inPlace := mat.DenseCopyOf(w)
+			r.UpdateTo(inPlace, inPlace, 0.5, lambda, 10)
+			assert.Assert(t, mat.EqualApprox(inPlace, expected, 1e-12), "%s in place with λ=%g", name, lambda)
+		}
+	}
+}
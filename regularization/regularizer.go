@@ -0,0 +1,60 @@
+package regularization
+
+import (
+	"errors"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Regularizer is the weight penalty added to the cost of a network to reduce overfitting.
+// 'lambda' is the regularization parameter and 'n' the total size of the training data set.
+// UpdateTo is the in-place counterpart of Update: it writes the updated weights into 'dst', which may be 'w' itself,
+// without allocating, for the training loops reusing their matrices from one mini-batch to the next.
+type Regularizer interface {
+	Cost(weights []mat.Matrix, lambda float64, n int) float64
+	Update(w mat.Matrix, eta, lambda float64, n int) mat.Matrix
+	UpdateTo(dst *mat.Dense, w mat.Matrix, eta, lambda float64, n int)
+	GetName() string
+}
+
+// New ...
+func New(name string) (Regularizer, error) {
+	switch name {
+	case L2:
+		return L2Regularizer{Name: name}, nil
+	case L1:
+		return L1Regularizer{Name: name}, nil
+	case ELASTIC_NET:
+		return ElasticNetRegularizer{Name: name, Ratio: 0.5}, nil
+	default:
+		return nil, errors.New("unavailable regularizer")
+	}
+}
+
+// utility functions
+
+// updateTo writes `fn(v)` for each value 'v' of 'w' into 'dst', which may be 'w' itself.
+func updateTo(dst *mat.Dense, w mat.Matrix, fn func(v float64) float64) {
+	r, c := w.Dims()
+	if dst.IsEmpty() {
+		dst.ReuseAs(r, c)
+	} else if dr, dc := dst.Dims(); dr != r || dc != c {
+		panic(mat.ErrShape)
+	}
+	raw := dst.RawMatrix()
+	src, ok := w.(*mat.Dense)
+	for i := 0; i < r; i++ {
+		row := raw.Data[i*raw.Stride : i*raw.Stride+c]
+		if ok {
+			from := src.RawMatrix()
+			copy(row, from.Data[i*from.Stride:i*from.Stride+c])
+		} else {
+			for j := range row {
+				row[j] = w.At(i, j)
+			}
+		}
+		for j, v := range row {
+			row[j] = fn(v)
+		}
+	}
+}
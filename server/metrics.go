@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"neuraldeep/metrics"
+	"strconv"
+	"time"
+)
+
+// The server exposes its own metrics at `GET /metrics` in the Prometheus text format: the number of requests by route
+// and status code, their latency, the distribution of the predicted classes and the version of the served model.
+
+//--- TYPES
+
+type serverMetrics struct {
+	requests    *metrics.Metric
+	latency     *metrics.Metric
+	predictions *metrics.Metric
+	version     *metrics.Metric
+	reloads     *metrics.Metric
+}
+
+// statusRecorder keeps the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+//--- METHODS
+
+// instrument wraps the handler of the 'route' to count its requests and measure their latency.
+func (s *Server) instrument(route string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h(rec, r)
+		s.metrics.requests.With(route, strconv.Itoa(rec.status)).Inc()
+		s.metrics.latency.With(route).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+//--- FUNCTIONS
+
+func newServerMetrics(r *metrics.Registry) *serverMetrics {
+	return &serverMetrics{
+		requests:    r.Counter("neuraldeep_http_requests_total", "Number of HTTP requests by route and status code.", "route", "code"),
+		latency:     r.Histogram("neuraldeep_http_request_duration_seconds", "Latency of the HTTP requests by route.", metrics.DEFAULT_BUCKETS, "route"),
+		predictions: r.Counter("neuraldeep_predictions_total", "Number of predictions by predicted class.", "class"),
+		version:     r.Gauge("neuraldeep_model_version", "Version of the served model, incremented at each reload."),
+		reloads:     r.Counter("neuraldeep_model_reloads_total", "Number of attempts to reload a changed model by result.", "result"),
+	}
+}
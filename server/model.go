@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"neuraldeep/cost"
+	"neuraldeep/network"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Once trained, a network is only read by its FeedForward() method, which allocates new matrices at each layer:
+// the same Model can thus be shared by all the goroutines of the HTTP server without any lock.
+
+//--- TYPES
+
+// Model wraps a trained network with the metadata exposed by the server.
+// 'Version' is incremented at each reload of the model, and 'Checksum' is the SHA-256 of the file it was loaded from.
+type Model struct {
+	Network       string    `json:"network"`
+	Sizes         []int     `json:"sizes"`
+	Cost          string    `json:"cost"`
+	Precision     string    `json:"precision,omitempty"`
+	Initializer   string    `json:"initializer,omitempty"`
+	Dropout       []float64 `json:"dropout,omitempty"`
+	BatchNorm     bool      `json:"batchNorm"`
+	Preprocessing []string  `json:"preprocessing,omitempty"`
+	Version       int       `json:"version"`
+	Checksum      string    `json:"checksum,omitempty"`
+	LoadedAt      time.Time `json:"loadedAt"`
+	predict       func(x []float64) mat.Matrix
+}
+
+// Prediction is the output of the network for one input: 'Label' is the index of the neuron with the highest activation.
+type Prediction struct {
+	Label   int       `json:"label"`
+	Outputs []float64 `json:"outputs"`
+}
+
+//--- METHODS
+
+// InputSize returns the number of values expected for each input.
+func (m *Model) InputSize() int {
+	return m.Sizes[0]
+}
+
+// OutputSize returns the number of values of each prediction.
+func (m *Model) OutputSize() int {
+	return m.Sizes[len(m.Sizes)-1]
+}
+
+// Predict feeds the passed raw input to the network.
+func (m *Model) Predict(x []float64) (p Prediction, err error) {
+	if len(x) != m.InputSize() {
+		err = fmt.Errorf("invalid input size: expected %d values, got %d", m.InputSize(), len(x))
+		return
+	}
+	output := m.predict(x)
+	_, c := output.Dims()
+	if c != m.OutputSize() {
+		err = errors.New("size mismatch in result")
+		return
+	}
+	p.Outputs = make([]float64, c)
+	for i := 0; i < c; i++ {
+		p.Outputs[i] = output.At(0, i)
+		if p.Outputs[i] > p.Outputs[p.Label] {
+			p.Label = i
+		}
+	}
+	return
+}
+
+//--- FUNCTIONS
+
+// FromNetwork1 wraps a trained Network1, whose raw inputs are fed as is and whose cost is always the quadratic one.
+func FromNetwork1(net *network.Network1) *Model {
+	return &Model{
+		Network: "1",
+		Sizes:   net.Sizes,
+		Cost:    cost.QUADRATIC_COST,
+		predict: func(x []float64) mat.Matrix {
+			return net.FeedForward(mat.NewVecDense(len(x), x))
+		},
+	}
+}
+
+// FromNetwork2 wraps a trained Network2, whose raw inputs go through its preprocessing pipeline if any.
+func FromNetwork2(net *network.Network2) *Model {
+	m := &Model{
+		Network:     "2",
+		Sizes:       net.Sizes,
+		Precision:   net.Precision(),
+		Initializer: net.Initializer(),
+		Dropout:     net.Dropout(),
+		BatchNorm:   len(net.BatchNorm()) > 0,
+		predict:     net.Predict,
+	}
+	if net.Cost != nil {
+		m.Cost = net.Cost.GetName()
+	}
+	for _, t := range net.Preprocessing() {
+		m.Preprocessing = append(m.Preprocessing, t.GetName())
+	}
+	return m
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// When the model is served from a file, the server polls it and reloads the model as soon as its checksum changes:
+// the new model is validated before being swapped in, so that a failed training or a file still being written
+// never replaces a working model. Requests being processed keep the model they started with.
+
+const DEFAULT_WATCH_INTERVAL = 10 * time.Second
+
+//--- TYPES
+
+// Loader reads the model saved at 'path', which may be a file or a folder (eg. the layers of a Network1).
+type Loader func(path string) (*Model, error)
+
+//--- METHODS
+
+// Reload loads the model at the server's path if it changed since the last load, and swaps it in if it's valid.
+// It returns `true` if a new model is now served.
+func (s *Server) Reload() (reloaded bool, err error) {
+	if s.load == nil {
+		return false, errors.New("the model wasn't loaded from a file")
+	}
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+
+	current := s.Model()
+	sum, err := checksum(s.path)
+	if err != nil {
+		return
+	}
+	if current != nil && sum == current.Checksum {
+		return
+	}
+	defer func() {
+		if current == nil {
+			return
+		}
+		if err != nil {
+			s.metrics.reloads.With("failure").Inc()
+		} else {
+			s.metrics.reloads.With("success").Inc()
+		}
+	}()
+	model, err := s.loadModel(s.path, current)
+	if err != nil {
+		return
+	}
+	// The file changed while it was read
+	if after, e := checksum(s.path); e != nil || after != sum {
+		return false, errors.New("the model changed while being loaded")
+	}
+	model.Checksum = sum
+	model.LoadedAt = time.Now()
+	model.Version = 1
+	if current != nil {
+		model.Version = current.Version + 1
+	}
+	s.model.Store(model)
+	s.metrics.version.With().Set(float64(model.Version))
+	return true, nil
+}
+
+// Watch reloads the model every 'interval' until the context is done, logging the reloads and failures to 'logger'.
+// A failed reload keeps the current model and is retried at the next tick.
+func (s *Server) Watch(ctx context.Context, interval time.Duration, logger io.Writer) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.Reload()
+			if err != nil {
+				// Only log a failure once until it changes
+				if err.Error() != lastErr {
+					fmt.Fprintf(logger, "failed to reload %s, still serving version %d: %v\n", s.path, s.Model().Version, err)
+				}
+				lastErr = err.Error()
+				continue
+			}
+			lastErr = ""
+			if reloaded {
+				m := s.Model()
+				fmt.Fprintf(logger, "reloaded %s: serving version %d [checksum=%s]\n", s.path, m.Version, m.Checksum)
+			}
+		}
+	}
+}
+
+// loadModel calls the loader and validates its result against the 'current' model,
+// turning panics into errors as a corrupted file mustn't stop the server.
+func (s *Server) loadModel(path string, current *Model) (model *Model, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			model, err = nil, fmt.Errorf("invalid model: %v", r)
+		}
+	}()
+	if model, err = s.load(path); err != nil {
+		return
+	}
+	err = validate(model, current)
+	return
+}
+
+//--- FUNCTIONS
+
+// NewFromFile returns a server for the model loaded from 'path' with the passed loader, which can be hot reloaded.
+func NewFromFile(path string, load Loader) (*Server, error) {
+	s := New(nil)
+	s.path = path
+	s.load = load
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// checksum returns the hex-encoded SHA-256 of the file at 'path', or of the regular files of the folder in name order.
+func checksum(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return "", err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+	h := sha256.New()
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, filepath.Base(file))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// validate checks that the new model answers finite predictions for the same input and output sizes as the current one.
+func validate(model, current *Model) error {
+	if model == nil || len(model.Sizes) < 2 {
+		return errors.New("invalid model: not enough layers")
+	}
+	if current != nil {
+		if model.InputSize() != current.InputSize() || model.OutputSize() != current.OutputSize() {
+			return fmt.Errorf("invalid model: sizes %v are incompatible with the served %v", model.Sizes, current.Sizes)
+		}
+	}
+	p, err := model.Predict(make([]float64, model.InputSize()))
+	if err != nil {
+		return err
+	}
+	for _, o := range p.Outputs {
+		if math.IsNaN(o) || math.IsInf(o, 0) {
+			return errors.New("invalid model: non-finite output")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"neuraldeep/metrics"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The inference server exposes a trained model through the following JSON endpoints:
+// - `GET /health` tells whether the server is up;
+// - `GET /model` returns the metadata of the model, eg. its sizes and cost function;
+// - `POST /predict` takes a `{"data": [...]}` input and returns its prediction;
+// - `POST /predict/batch` takes an `{"inputs": [[...], ...]}` list and returns the predictions in the same order;
+// - `POST /reload` reloads the model from its file if it changed;
+// - `GET /metrics` exposes the metrics of the server to Prometheus.
+// The version of the model that answered is passed in the `X-Model-Version` header of the predictions.
+
+const (
+	DEFAULT_MAX_BODY_SIZE  = 8 << 20
+	DEFAULT_MAX_BATCH_SIZE = 1000
+)
+
+//--- TYPES
+
+// Server answers predictions of a model over HTTP.
+// Request bodies bigger than 'MaxBodySize' bytes and batches of more than 'MaxBatchSize' inputs are rejected.
+type Server struct {
+	MaxBodySize  int64
+	MaxBatchSize int
+	model        atomic.Pointer[Model]
+	path         string
+	load         Loader
+	reloading    sync.Mutex
+	registry     *metrics.Registry
+	metrics      *serverMetrics
+	started      time.Time
+}
+
+// PredictRequest ...
+type PredictRequest struct {
+	Data []float64 `json:"data"`
+}
+
+// BatchRequest ...
+type BatchRequest struct {
+	Inputs [][]float64 `json:"inputs"`
+}
+
+// BatchResponse ...
+type BatchResponse struct {
+	Predictions []Prediction `json:"predictions"`
+}
+
+// Health ...
+type Health struct {
+	Status   string  `json:"status"`
+	Uptime   float64 `json:"uptime"`
+	Version  int     `json:"version"`
+	Checksum string  `json:"checksum,omitempty"`
+}
+
+// errorResponse ...
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+//--- METHODS
+
+// Handler returns the routes of the server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.instrument("/health", s.health))
+	mux.HandleFunc("GET /model", s.instrument("/model", s.metadata))
+	mux.HandleFunc("POST /predict", s.instrument("/predict", s.predict))
+	mux.HandleFunc("POST /predict/batch", s.instrument("/predict/batch", s.predictBatch))
+	mux.HandleFunc("POST /reload", s.instrument("/reload", s.reload))
+	mux.Handle("GET /metrics", s.registry.Handler())
+	return mux
+}
+
+// ListenAndServe listens on the TCP network address 'addr', eg. ":8080", until the server fails.
+func (s *Server) ListenAndServe(addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+	return srv.ListenAndServe()
+}
+
+// Metrics returns the registry of the server's metrics, eg. to add the ones of a training.
+func (s *Server) Metrics() *metrics.Registry {
+	return s.registry
+}
+
+// Model returns the model currently served.
+func (s *Server) Model() *Model {
+	return s.model.Load()
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	model := s.Model()
+	writeJSON(w, http.StatusOK, Health{
+		Status:   "ok",
+		Uptime:   time.Since(s.started).Seconds(),
+		Version:  model.Version,
+		Checksum: model.Checksum,
+	})
+}
+
+func (s *Server) metadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Model())
+}
+
+func (s *Server) predict(w http.ResponseWriter, r *http.Request) {
+	var req PredictRequest
+	if !s.decode(w, r, &req) {
+		return
+	}
+	model := s.Model()
+	w.Header().Set("X-Model-Version", strconv.Itoa(model.Version))
+	p, err := model.Predict(req.Data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.metrics.predictions.With(strconv.Itoa(p.Label)).Inc()
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) predictBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if !s.decode(w, r, &req) {
+		return
+	}
+	if len(req.Inputs) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("empty batch"))
+		return
+	}
+	if s.MaxBatchSize > 0 && len(req.Inputs) > s.MaxBatchSize {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("too many inputs: the maximum batch size is %d", s.MaxBatchSize))
+		return
+	}
+	// Use the same model for the whole batch
+	model := s.Model()
+	w.Header().Set("X-Model-Version", strconv.Itoa(model.Version))
+	res := BatchResponse{
+		Predictions: make([]Prediction, len(req.Inputs)),
+	}
+	for i, x := range req.Inputs {
+		p, err := model.Predict(x)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("input #%d: %w", i, err))
+			return
+		}
+		res.Predictions[i] = p
+	}
+	for _, p := range res.Predictions {
+		s.metrics.predictions.With(strconv.Itoa(p.Label)).Inc()
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) reload(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.Reload(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Model())
+}
+
+// decode reads the JSON body of the request into 'v', writing the error response and returning `false` if it failed.
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if s.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.MaxBodySize)
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body too large: the limit is %d bytes", tooLarge.Limit))
+		} else {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		}
+		return false
+	}
+	return true
+}
+
+//--- FUNCTIONS
+
+// New returns a server for the passed model with the default limits. Use NewFromFile() to be able to reload the model.
+func New(model *Model) *Server {
+	registry := metrics.NewRegistry()
+	s := &Server{
+		MaxBodySize:  DEFAULT_MAX_BODY_SIZE,
+		MaxBatchSize: DEFAULT_MAX_BATCH_SIZE,
+		registry:     registry,
+		metrics:      newServerMetrics(registry),
+		started:      time.Now(),
+	}
+	if model != nil {
+		model.Version = 1
+		model.LoadedAt = s.started
+		s.model.Store(model)
+		s.metrics.version.With().Set(1)
+	}
+	return s
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
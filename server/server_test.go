@@ -0,0 +1,181 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"neuraldeep/network"
+	"neuraldeep/server"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestServer ...
+func TestServer(t *testing.T) {
+	net, err := network.Initial([]int{4, 5, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := server.New(server.FromNetwork2(net))
+	s.MaxBatchSize = 2
+	s.MaxBodySize = 1 << 10
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+
+	var model server.Model
+	res, err = http.Get(ts.URL + "/model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&model); err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, model.Sizes, []int{4, 5, 3})
+	assert.Equal(t, model.Cost, "crossEntropy")
+
+	expected := net.Predict([]float64{1, 2, 3, 4})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var p server.Prediction
+			res, err := http.Post(ts.URL+"/predict", "application/json", strings.NewReader(`{"data":[1,2,3,4]}`))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer res.Body.Close()
+			if err := json.NewDecoder(res.Body).Decode(&p); err != nil {
+				t.Error(err)
+				return
+			}
+			for j, v := range p.Outputs {
+				if v != expected.At(0, j) {
+					t.Errorf("output #%d: expected %f, got %f", j, expected.At(0, j), v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var batch server.BatchResponse
+	res, err = http.Post(ts.URL+"/predict/batch", "application/json", strings.NewReader(`{"inputs":[[1,2,3,4],[0,0,0,0]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	if err := json.NewDecoder(res.Body).Decode(&batch); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(batch.Predictions), 2)
+
+	for body, status := range map[string]int{
+		`{"data":[1,2,3]}`: http.StatusBadRequest,
+		`{"data":`:         http.StatusBadRequest,
+		`{"data":[` + strings.Repeat("0,", 600) + `0]}`: http.StatusRequestEntityTooLarge,
+	} {
+		res, err := http.Post(ts.URL+"/predict", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, res.StatusCode, status)
+	}
+	res, err = http.Post(ts.URL+"/predict/batch", "application/json", strings.NewReader(`{"inputs":[[1,2,3,4],[1,2,3,4],[1,2,3,4]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, res.StatusCode, http.StatusRequestEntityTooLarge)
+
+	res, err = http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	for _, line := range []string{
+		`neuraldeep_http_requests_total{route="/predict",code="200"} 8`,
+		`neuraldeep_http_requests_total{route="/predict",code="400"} 2`,
+		`neuraldeep_http_requests_total{route="/predict/batch",code="413"} 1`,
+		`neuraldeep_http_request_duration_seconds_count{route="/health"} 1`,
+		`neuraldeep_model_version 1`,
+	} {
+		assert.Assert(t, strings.Contains(string(body), line+"\n"), line)
+	}
+	assert.Assert(t, strings.Contains(string(body), "# TYPE neuraldeep_predictions_total counter"))
+}
+
+// TestReload ...
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "network2.json")
+	save := func(sizes []int) {
+		net, err := network.Initial(sizes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := net.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func(path string) (*server.Model, error) {
+		net, err := network.Initial([]int{4, 5, 3})
+		if err != nil {
+			return nil, err
+		}
+		if err := net.Load(path); err != nil {
+			return nil, err
+		}
+		return server.FromNetwork2(net), nil
+	}
+	save([]int{4, 5, 3})
+	s, err := server.NewFromFile(path, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s.Model()
+	assert.Equal(t, first.Version, 1)
+
+	// Unchanged file
+	reloaded, err := s.Reload()
+	assert.NilError(t, err)
+	assert.Assert(t, !reloaded)
+
+	// New training
+	save([]int{4, 8, 3})
+	reloaded, err = s.Reload()
+	assert.NilError(t, err)
+	assert.Assert(t, reloaded)
+	assert.Equal(t, s.Model().Version, 2)
+	assert.DeepEqual(t, s.Model().Sizes, []int{4, 8, 3})
+	assert.Assert(t, s.Model().Checksum != first.Checksum)
+
+	// Invalid models are never swapped in
+	if err := os.WriteFile(path, []byte(`{"sizes":[4,`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Reload()
+	assert.Assert(t, err != nil)
+	save([]int{4, 5, 2})
+	_, err = s.Reload()
+	assert.ErrorContains(t, err, "incompatible")
+	assert.Equal(t, s.Model().Version, 2)
+
+	var exposition strings.Builder
+	s.Metrics().WriteText(&exposition)
+	assert.Assert(t, strings.Contains(exposition.String(), `neuraldeep_model_reloads_total{result="failure"} 2`))
+	assert.Assert(t, strings.Contains(exposition.String(), `neuraldeep_model_reloads_total{result="success"} 1`))
+	assert.Assert(t, strings.Contains(exposition.String(), "neuraldeep_model_version 2"))
+}
@@ -0,0 +1,102 @@
+package tuning
+
+import (
+	"errors"
+	"fmt"
+	"neuraldeep/network"
+	"neuraldeep/preprocess"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// EARLY_STOPPING_FOLDS is the number of folds the training data of each cross-validation fold is split into when early
+// stopping is enabled, one of them being held out to decide when to stop, ie. a tenth of the data.
+const EARLY_STOPPING_FOLDS = 10
+
+//--- TYPES
+
+// CrossValidation holds the results of a k-fold cross-validation.
+// The accuracies are expressed as the ratio of correct results in each validation fold since folds may differ in size.
+type CrossValidation struct {
+	Trials         []Trial
+	Accuracies     []float64
+	Costs          []float64
+	MeanAccuracy   float64
+	StdDevAccuracy float64
+	MeanCost       float64
+	StdDevCost     float64
+}
+
+//--- FUNCTIONS
+
+// CrossValidate trains a fresh network with the hyper-parameters of the passed candidate on the training data of each fold,
+// and evaluates it on the fold's validation data, both being preprocessed by a pipeline fitted on the training data alone
+// if the configuration has one. With early stopping, the validation data of the fold isn't the one that decides when
+// to stop, which would bias its score: a stratified share of the training data is held out for it instead
+// (see EARLY_STOPPING_FOLDS), and the network of the best epoch on that share is the one evaluated.
+func CrossValidate(folds []network.Fold, candidate Candidate, config Config) (cv CrossValidation, err error) {
+	if len(folds) == 0 {
+		err = errors.New("no fold to validate")
+		return
+	}
+	if config.New == nil {
+		err = errors.New("missing network factory")
+		return
+	}
+	for i, fold := range folds {
+		if len(fold.Validation) == 0 {
+			err = fmt.Errorf("empty validation data in fold %d", i+1)
+			return
+		}
+		fmt.Printf("fold %d / %d: training on %d inputs, validating on %d\n", i+1, len(folds), len(fold.Training), len(fold.Validation))
+		training, validation := fold.Training, fold.Validation
+		if config.Preprocessing != nil {
+			if training, validation, err = preprocessFold(config.Preprocessing, fold); err != nil {
+				return
+			}
+		}
+		var stopping network.Dataset
+		if config.EarlyStopping > 0 {
+			inner, e := training.StratifiedKFold(EARLY_STOPPING_FOLDS)
+			if e != nil {
+				err = fmt.Errorf("fold %d: %w", i+1, e)
+				return
+			}
+			training, stopping = inner[0].Training, inner[0].Validation
+		}
+		net, trial, e := train(candidate, training, stopping, config)
+		if e != nil {
+			err = e
+			return
+		}
+		trial.Accuracy = net.Accuracy(validation)
+		trial.Cost = net.TotalCost(validation, candidate.Lambda)
+		cv.Trials = append(cv.Trials, trial)
+		cv.Accuracies = append(cv.Accuracies, float64(trial.Accuracy)/float64(len(fold.Validation)))
+		cv.Costs = append(cv.Costs, trial.Cost)
+	}
+	cv.MeanAccuracy, cv.StdDevAccuracy = meanStdDev(cv.Accuracies)
+	cv.MeanCost, cv.StdDevCost = meanStdDev(cv.Costs)
+	return
+}
+
+// utility functions
+
+// preprocessFold fits a new pipeline on the training data of the fold and returns both sides of the fold transformed by it.
+func preprocessFold(newPipeline func() (preprocess.Pipeline, error), fold network.Fold) (training, validation network.Dataset, err error) {
+	p, err := newPipeline()
+	if err != nil {
+		return
+	}
+	if err = fold.Training.Fit(p); err != nil {
+		return
+	}
+	return fold.Training.Transform(p), fold.Validation.Transform(p), nil
+}
+
+func meanStdDev(values []float64) (mean, stdDev float64) {
+	if len(values) == 1 {
+		return values[0], 0
+	}
+	return stat.MeanStdDev(values, nil)
+}
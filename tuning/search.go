@@ -0,0 +1,170 @@
+package tuning
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"neuraldeep/network"
+	"neuraldeep/preprocess"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//--- TYPES
+
+// Factory builds a new untrained network for the passed layer sizes, eg. with the cost function and regularizer to use.
+type Factory func(sizes []int) (*network.Network2, error)
+
+// Config holds the settings shared by all the trials of a search.
+// The hidden layers of 'Sizes' are resized to each candidate's width, and every network is trained
+// for at most 'Epochs' epochs, stopping early after 'EarlyStopping' epochs without improvement (0 to disable).
+// If set, 'Preprocessing' returns a new unfitted pipeline that CrossValidate() fits on the training data of each fold
+// before applying it to both sides of the fold, so that the validation data never leaks into the fitting.
+type Config struct {
+	Sizes         []int
+	Epochs        int
+	EarlyStopping int
+	New           Factory
+	Preprocessing func() (preprocess.Pipeline, error)
+}
+
+// Trial is the outcome of training a candidate, evaluated on the validation data at its best epoch, ie. the first one
+// reaching the highest accuracy, out of the 'Epochs' it was trained for.
+type Trial struct {
+	Candidate
+	Accuracy  int           `json:"accuracy"`
+	Cost      float64       `json:"cost"`
+	BestEpoch int           `json:"bestEpoch"`
+	Epochs    int           `json:"epochs"`
+	Duration  time.Duration `json:"duration"`
+}
+
+//--- FUNCTIONS
+
+// Search trains a network for each candidate on the 'training' data and returns the trials ranked by decreasing accuracy
+// (then increasing cost) on the 'validation' data, alongside the best network, with the parameters of its best epoch.
+// Only the best network so far is kept in memory.
+func Search(candidates []Candidate, training, validation network.Dataset, config Config) (leaderboard []Trial, best *network.Network2, err error) {
+	if len(candidates) == 0 {
+		err = errors.New("no candidate to try")
+		return
+	}
+	if len(validation) == 0 {
+		err = errors.New("empty validation data")
+		return
+	}
+	if config.New == nil {
+		err = errors.New("missing network factory")
+		return
+	}
+	for i, candidate := range candidates {
+		fmt.Printf("trial %d / %d: eta=%f lambda=%f size=%d hidden=%d\n", i+1, len(candidates), candidate.Eta, candidate.Lambda, candidate.MiniBatchSize, candidate.Hidden)
+		net, trial, e := train(candidate, training, validation, config)
+		if e != nil {
+			err = e
+			return
+		}
+		if best == nil || better(trial, leaderboard[0]) {
+			best = net
+		}
+		leaderboard = append(leaderboard, trial)
+		sort.SliceStable(leaderboard, func(i, j int) bool {
+			return better(leaderboard[i], leaderboard[j])
+		})
+	}
+	return
+}
+
+// better tells whether the trial 'a' ranks before the trial 'b'.
+func better(a, b Trial) bool {
+	if a.Accuracy != b.Accuracy {
+		return a.Accuracy > b.Accuracy
+	}
+	return a.Cost < b.Cost
+}
+
+// train fits a new network with the hyper-parameters of the passed candidate and evaluates it against the 'validation' data
+// at each epoch, returning the network restored to its best epoch rather than the last one, which early stopping makes
+// a few epochs past the best. Without validation data, the network is trained for all the epochs and returned as is.
+func train(candidate Candidate, training, validation network.Dataset, config Config) (net *network.Network2, trial Trial, err error) {
+	sizes := make([]int, len(config.Sizes))
+	copy(sizes, config.Sizes)
+	if candidate.Hidden > 0 {
+		for l := 1; l < len(sizes)-1; l++ {
+			sizes[l] = candidate.Hidden
+		}
+	}
+	net, err = config.New(sizes)
+	if err != nil {
+		return
+	}
+	net.SetEarlyStopping(config.EarlyStopping)
+	best, bestAccuracy := net, -1.
+	net.OnEpoch(func(e network.Epoch) {
+		if e.EvaluationAccuracy == nil || *e.EvaluationAccuracy <= bestAccuracy || err != nil {
+			return
+		}
+		bestAccuracy = *e.EvaluationAccuracy
+		best, err = net.Clone()
+	})
+	t0 := time.Now()
+	monitor := len(validation) > 0
+	evaluationCost, evaluationAccuracy, _, _, _ := net.SGD(training, config.Epochs, candidate.MiniBatchSize, candidate.Eta, candidate.Lambda, validation, monitor, monitor)
+	if err != nil {
+		return
+	}
+	trial = Trial{
+		Candidate: candidate,
+		Duration:  time.Since(t0),
+		Epochs:    len(evaluationAccuracy),
+	}
+	if len(validation) == 0 {
+		trial.Epochs, trial.BestEpoch = config.Epochs, config.Epochs
+	}
+	for j, accuracy := range evaluationAccuracy {
+		if j == 0 || accuracy > trial.Accuracy {
+			trial.Accuracy, trial.Cost, trial.BestEpoch = accuracy, evaluationCost[j], j+1
+		}
+	}
+	net = best
+	return
+}
+
+// WriteLeaderboard saves the ranked trials as a CSV file to 'path', creating the parent folders if needed.
+func WriteLeaderboard(leaderboard []Trial, total int, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err = w.Write([]string{"rank", "eta", "lambda", "miniBatchSize", "hidden", "accuracy", "total", "cost", "bestEpoch", "epochs", "seconds"}); err != nil {
+		return err
+	}
+	for i, trial := range leaderboard {
+		record := []string{
+			strconv.Itoa(i + 1),
+			strconv.FormatFloat(trial.Eta, 'g', -1, 64),
+			strconv.FormatFloat(trial.Lambda, 'g', -1, 64),
+			strconv.Itoa(trial.MiniBatchSize),
+			strconv.Itoa(trial.Hidden),
+			strconv.Itoa(trial.Accuracy),
+			strconv.Itoa(total),
+			strconv.FormatFloat(trial.Cost, 'f', 6, 64),
+			strconv.Itoa(trial.BestEpoch),
+			strconv.Itoa(trial.Epochs),
+			strconv.FormatFloat(trial.Duration.Seconds(), 'f', 3, 64),
+		}
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,138 @@
+package tuning
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+const (
+	GRID_SEARCH   = "grid"
+	RANDOM_SEARCH = "random"
+)
+
+//--- TYPES
+
+// Space defines the values of the hyper-parameters to explore.
+// With a grid search, every combination of the listed values is tried.
+// With a random search, the lists are used as ranges: the learning rate 'eta' and the regularization parameter 'lambda'
+// are drawn log-uniformly (or uniformly if the range includes 0) between their minimum and maximum values,
+// and the mini-batch size and the hidden width uniformly between theirs.
+type Space struct {
+	Etas           []float64
+	Lambdas        []float64
+	MiniBatchSizes []int
+	HiddenSizes    []int
+}
+
+// Candidate is a set of hyper-parameters to try.
+// 'Hidden' is the number of neurons of every hidden layer of the network, 0 meaning that the configured sizes are kept.
+type Candidate struct {
+	Eta           float64 `json:"eta"`
+	Lambda        float64 `json:"lambda"`
+	MiniBatchSize int     `json:"miniBatchSize"`
+	Hidden        int     `json:"hidden"`
+}
+
+//--- METHODS
+
+// Grid returns all the combinations of the values of the space.
+func (s Space) Grid() (candidates []Candidate, err error) {
+	if err = s.validate(); err != nil {
+		return
+	}
+	for _, eta := range s.Etas {
+		for _, lambda := range s.Lambdas {
+			for _, size := range s.MiniBatchSizes {
+				for _, hidden := range s.HiddenSizes {
+					candidates = append(candidates, Candidate{
+						Eta:           eta,
+						Lambda:        lambda,
+						MiniBatchSize: size,
+						Hidden:        hidden,
+					})
+				}
+			}
+		}
+	}
+	return
+}
+
+// Random returns 'n' candidates drawn from the ranges of the space using the passed random generator.
+func (s Space) Random(n int, rng *rand.Rand) (candidates []Candidate, err error) {
+	if err = s.validate(); err != nil {
+		return
+	}
+	if n < 1 {
+		err = errors.New("the number of trials must be positive")
+		return
+	}
+	for i := 0; i < n; i++ {
+		candidates = append(candidates, Candidate{
+			Eta:           logUniform(s.Etas, rng),
+			Lambda:        logUniform(s.Lambdas, rng),
+			MiniBatchSize: uniformInt(s.MiniBatchSizes, rng),
+			Hidden:        uniformInt(s.HiddenSizes, rng),
+		})
+	}
+	return
+}
+
+func (s Space) validate() error {
+	if len(s.Etas) == 0 || len(s.Lambdas) == 0 || len(s.MiniBatchSizes) == 0 || len(s.HiddenSizes) == 0 {
+		return errors.New("every hyper-parameter needs at least one value")
+	}
+	for _, eta := range s.Etas {
+		if eta <= 0 {
+			return errors.New("learning rates must be positive")
+		}
+	}
+	for _, lambda := range s.Lambdas {
+		if lambda < 0 {
+			return errors.New("regularization parameters can't be negative")
+		}
+	}
+	for _, size := range s.MiniBatchSizes {
+		if size < 1 {
+			return errors.New("mini-batch sizes must be positive")
+		}
+	}
+	for _, hidden := range s.HiddenSizes {
+		if hidden < 1 {
+			return errors.New("hidden layer widths must be positive")
+		}
+	}
+	return nil
+}
+
+// utility functions
+
+func bounds(values []float64) (min, max float64) {
+	min, max = values[0], values[0]
+	for _, v := range values[1:] {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	return
+}
+
+func logUniform(values []float64, rng *rand.Rand) float64 {
+	min, max := bounds(values)
+	if min <= 0 {
+		return min + rng.Float64()*(max-min)
+	}
+	return math.Exp(math.Log(min) + rng.Float64()*(math.Log(max)-math.Log(min)))
+}
+
+func uniformInt(values []int, rng *rand.Rand) int {
+	min, max := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min + rng.Intn(max-min+1)
+}
@@ -0,0 +1,162 @@
+package tuning_test
+
+import (
+	"math/rand"
+	"neuraldeep/network"
+	"neuraldeep/preprocess"
+	"neuraldeep/tuning"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestGrid ...
+func TestGrid(t *testing.T) {
+	space := tuning.Space{
+		Etas:           []float64{0.1, 0.5},
+		Lambdas:        []float64{0, 1, 5},
+		MiniBatchSizes: []int{10},
+		HiddenSizes:    []int{30, 100},
+	}
+	candidates, err := space.Grid()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(candidates), 12)
+	assert.DeepEqual(t, candidates[0], tuning.Candidate{Eta: 0.1, Lambda: 0, MiniBatchSize: 10, Hidden: 30})
+
+	_, err = tuning.Space{Etas: []float64{0.1}}.Grid()
+	assert.Error(t, err, "every hyper-parameter needs at least one value")
+}
+
+// TestRandom ...
+func TestRandom(t *testing.T) {
+	space := tuning.Space{
+		Etas:           []float64{0.01, 1},
+		Lambdas:        []float64{0, 5},
+		MiniBatchSizes: []int{5, 20},
+		HiddenSizes:    []int{30},
+	}
+	candidates, err := space.Random(50, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(candidates), 50)
+	for _, c := range candidates {
+		assert.Assert(t, c.Eta >= 0.01 && c.Eta <= 1)
+		assert.Assert(t, c.Lambda >= 0 && c.Lambda <= 5)
+		assert.Assert(t, c.MiniBatchSize >= 5 && c.MiniBatchSize <= 20)
+		assert.Equal(t, c.Hidden, 30)
+	}
+}
+
+// TestSearch ...
+func TestSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var data network.Dataset
+	for i := 0; i < 120; i++ {
+		x := []float64{r.Float64(), r.Float64()}
+		label := 0.
+		if x[0] > x[1] {
+			label = 1.
+		}
+		data = append(data, &network.Input{Data: x, Label: network.ToLabel(label, 2)})
+	}
+	candidates := []tuning.Candidate{
+		{Eta: 0.0001, Lambda: 0, MiniBatchSize: 10, Hidden: 2},
+		{Eta: 3.0, Lambda: 0, MiniBatchSize: 10, Hidden: 8},
+	}
+	config := tuning.Config{
+		Sizes:         []int{2, 4, 2},
+		Epochs:        20,
+		EarlyStopping: 5,
+		New: func(sizes []int) (*network.Network2, error) {
+			net, err := network.Initial(sizes)
+			if err != nil {
+				return nil, err
+			}
+			net.Seed(42)
+			return net, nil
+		},
+	}
+	leaderboard, best, err := tuning.Search(candidates, data[:100], data[100:], config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(leaderboard), 2)
+	assert.Assert(t, leaderboard[0].Accuracy >= leaderboard[1].Accuracy)
+	for _, trial := range leaderboard {
+		assert.Assert(t, trial.BestEpoch >= 1 && trial.BestEpoch <= trial.Epochs)
+	}
+	assert.Equal(t, leaderboard[0].Hidden, best.Sizes[1])
+	assert.Equal(t, best.Accuracy(data[100:]), leaderboard[0].Accuracy)
+
+	path := filepath.Join(t.TempDir(), "tuning", "leaderboard.csv")
+	if err := tuning.WriteLeaderboard(leaderboard, 20, path); err != nil {
+		t.Fatal(err)
+	}
+	_, err = os.Stat(path)
+	assert.NilError(t, err)
+}
+
+// TestCrossValidate ...
+func TestCrossValidate(t *testing.T) {
+	data := network.Synthetic(50, 2, 2, rand.New(rand.NewSource(1)))
+	folds, err := data.StratifiedKFold(5, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fitted []int
+	config := tuning.Config{
+		Sizes:  []int{2, 4, 2},
+		Epochs: 2,
+		New: func(sizes []int) (*network.Network2, error) {
+			return network.Initial(sizes)
+		},
+		Preprocessing: func() (preprocess.Pipeline, error) {
+			return preprocess.Pipeline{&sizeRecorder{fitted: &fitted}}, nil
+		},
+	}
+	cv, err := tuning.CrossValidate(folds, tuning.Candidate{Eta: 0.5, MiniBatchSize: 10}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(cv.Trials), 5)
+
+	// A pipeline per fold, fitted on its training data only
+	assert.Equal(t, len(fitted), 5)
+	for i, fold := range folds {
+		assert.Equal(t, fitted[i], len(fold.Training))
+	}
+
+	// Early stopping on a share of the training data, the validation fold only scoring the network of the best epoch
+	config.Preprocessing, config.EarlyStopping = nil, 1
+	cv, err = tuning.CrossValidate(folds, tuning.Candidate{Eta: 0.5, MiniBatchSize: 10}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, trial := range cv.Trials {
+		assert.Assert(t, trial.BestEpoch >= 1 && trial.BestEpoch <= trial.Epochs)
+		assert.Assert(t, trial.Accuracy <= len(folds[i].Validation))
+	}
+}
+
+// sizeRecorder is an identity transformer recording the size of the data it's fitted on.
+type sizeRecorder struct {
+	fitted *[]int
+}
+
+func (r *sizeRecorder) Fit(data [][]float64) error {
+	*r.fitted = append(*r.fitted, len(data))
+	return nil
+}
+
+func (r *sizeRecorder) Transform(x []float64) []float64 {
+	return x
+}
+
+func (r *sizeRecorder) GetName() string {
+	return "sizeRecorder"
+}
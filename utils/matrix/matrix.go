@@ -2,12 +2,18 @@ package matrix
 
 import (
 	"math"
+	"math/rand"
 
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
-	"gonum.org/v1/gonum/stat/distuv"
 )
 
 // see https://sausheong.github.io/posts/how-to-build-a-simple-artificial-neural-network-with-go
+//
+// Each operation returning a new matrix has a destination-passing variant suffixed with `To`, which writes its result
+// into the passed *mat.Dense and returns it: an empty destination is allocated on first use, then reused as is,
+// so that a training loop keeping its destinations from one input to the next doesn't allocate anymore.
+// Except for DotTo(), the destination may be one of the operands.
 
 // Add computes the addition of two matrices.
 func Add(m, n mat.Matrix) mat.Matrix {
@@ -17,6 +23,48 @@ func Add(m, n mat.Matrix) mat.Matrix {
 	return o
 }
 
+// AddTo computes the addition of two matrices into 'dst'.
+func AddTo(dst *mat.Dense, m, n mat.Matrix) *mat.Dense {
+	dst.Add(m, n)
+	return dst
+}
+
+// AddOuterTo computes `m + s·x·yᵀ` into 'dst', ie. adds the scaled outer product of the vectors 'x' and 'y' to 'm'.
+func AddOuterTo(dst *mat.Dense, m mat.Matrix, s float64, x, y mat.Vector) *mat.Dense {
+	dst.RankOne(m, s, x, y)
+	return dst
+}
+
+// AddScaledTo computes `m + s·n` into 'dst', which may be 'm' but not 'n'.
+func AddScaledTo(dst *mat.Dense, m mat.Matrix, s float64, n mat.Matrix) *mat.Dense {
+	r, c := m.Dims()
+	if rn, cn := n.Dims(); rn != r || cn != c {
+		panic(mat.ErrShape)
+	}
+	if dst.IsEmpty() {
+		dst.ReuseAs(r, c)
+	}
+	if rd, cd := dst.Dims(); rd != r || cd != c {
+		panic(mat.ErrShape)
+	}
+	if dst != m {
+		dst.Copy(m)
+	}
+	if nd, ok := n.(*mat.Dense); ok && nd != dst {
+		raw, rawN := dst.RawMatrix(), nd.RawMatrix()
+		for i := 0; i < r; i++ {
+			floats.AddScaled(raw.Data[i*raw.Stride:i*raw.Stride+c], s, rawN.Data[i*rawN.Stride:i*rawN.Stride+c])
+		}
+		return dst
+	}
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			dst.Set(i, j, dst.At(i, j)+s*n.At(i, j))
+		}
+	}
+	return dst
+}
+
 // Apply applies a function to each elements of a matrix.
 func Apply(fn func(i, j int, v float64) float64, m mat.Matrix) mat.Matrix {
 	r, c := m.Dims()
@@ -25,6 +73,25 @@ func Apply(fn func(i, j int, v float64) float64, m mat.Matrix) mat.Matrix {
 	return o
 }
 
+// ApplyTo applies a function to each elements of a matrix into 'dst'.
+func ApplyTo(dst *mat.Dense, fn func(i, j int, v float64) float64, m mat.Matrix) *mat.Dense {
+	dst.Apply(fn, m)
+	return dst
+}
+
+// ColumnSums returns the 1×c sum of the rows of the r×c matrix 'm', eg. the gradient of the biases added to each row
+// of a mini-batch.
+func ColumnSums(m mat.Matrix) *mat.Dense {
+	r, c := m.Dims()
+	sums := mat.NewDense(1, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			sums.Set(0, j, sums.At(0, j)+m.At(i, j))
+		}
+	}
+	return sums
+}
+
 // Dot computes the dot product between two matrices.
 func Dot(m, n mat.Matrix) mat.Matrix {
 	r, _ := m.Dims()
@@ -34,6 +101,27 @@ func Dot(m, n mat.Matrix) mat.Matrix {
 	return o
 }
 
+// DotTo computes the dot product between two matrices into 'dst', which mustn't be one of them.
+func DotTo(dst *mat.Dense, m, n mat.Matrix) *mat.Dense {
+	dst.Mul(m, n)
+	return dst
+}
+
+// Gaussian initializes a matrix of `r` rows and `c` columns with values drawn from a normal distribution
+// of mean `mean` and standard deviation `stdDev`.
+// If a random generator is passed, the values are drawn from it instead of the global one, eg. to reproduce them.
+func Gaussian(r, c int, mean, stdDev float64, rng ...*rand.Rand) mat.Matrix {
+	normal := rand.NormFloat64
+	if len(rng) > 0 && rng[0] != nil {
+		normal = rng[0].NormFloat64
+	}
+	data := make([]float64, r*c)
+	for i := 0; i < r*c; i++ {
+		data[i] = mean + stdDev*normal()
+	}
+	return mat.NewDense(r, c, data)
+}
+
 // Log applies the natural logarithm element-wise on the passed Matrix.
 func Log(m mat.Matrix) mat.Matrix {
 	return Apply(func(i, j int, v float64) float64 {
@@ -52,16 +140,60 @@ func Multiply(m, n mat.Matrix) mat.Matrix {
 	return o
 }
 
-// Random initializes a matrix of `r` rows and `c` columns with randomized values of mean `v`.
-func Random(r, c int, v float64) mat.Matrix {
-	boundary := 1 / math.Sqrt(v) // To get a variance of 1 around a mean of v
-	dist := distuv.Uniform{
-		Min: -boundary,
-		Max: boundary,
+// MultiplyTo multiplies two matrices together element-wise into 'dst'.
+func MultiplyTo(dst *mat.Dense, m, n mat.Matrix) *mat.Dense {
+	dst.MulElem(m, n)
+	return dst
+}
+
+// MulVecTo computes the product of a matrix by a vector into 'dst', which mustn't be the vector.
+func MulVecTo(dst *mat.VecDense, m mat.Matrix, v mat.Vector) *mat.VecDense {
+	dst.MulVec(m, v)
+	return dst
+}
+
+// Orthogonal initializes a matrix of `r` rows and `c` columns whose rows (or columns if `r` > `c`) are orthonormal,
+// scaled by `gain`. It is built from the QR decomposition of a standard Gaussian matrix, as described by Saxe et al.
+// If a random generator is passed, the Gaussian matrix is drawn from it instead of the global one.
+func Orthogonal(r, c int, gain float64, rng ...*rand.Rand) mat.Matrix {
+	m, n := r, c
+	if r < c {
+		m, n = c, r
+	}
+	var qr mat.QR
+	qr.Factorize(Gaussian(m, n, 0, 1, rng...))
+	var q, rr mat.Dense
+	qr.QTo(&q)
+	qr.RTo(&rr)
+	o := mat.NewDense(m, n, nil)
+	for j := 0; j < n; j++ {
+		// Make the decomposition unique, hence Q uniformly distributed, by forcing a positive diagonal on R
+		sign := 1.
+		if rr.At(j, j) < 0 {
+			sign = -1.
+		}
+		for i := 0; i < m; i++ {
+			o.Set(i, j, gain*sign*q.At(i, j))
+		}
+	}
+	if r < c {
+		return mat.DenseCopyOf(o.T())
+	}
+	return o
+}
+
+// Random initializes a matrix of `r` rows and `c` columns with values drawn uniformly in `[-1/√v, 1/√v]`.
+// Note that despite its use in the original networks, this is not a Gaussian distribution: use Gaussian() for that.
+// If a random generator is passed, the values are drawn from it instead of the global one.
+func Random(r, c int, v float64, rng ...*rand.Rand) mat.Matrix {
+	boundary := 1 / math.Sqrt(v)
+	uniform := rand.Float64
+	if len(rng) > 0 && rng[0] != nil {
+		uniform = rng[0].Float64
 	}
 	data := make([]float64, r*c)
 	for i := 0; i < r*c; i++ {
-		data[i] = dist.Rand()
+		data[i] = boundary * (2*uniform() - 1)
 	}
 	return mat.NewDense(r, c, data)
 }
@@ -74,6 +206,12 @@ func Scale(s float64, m mat.Matrix) mat.Matrix {
 	return o
 }
 
+// ScaleTo multiplies a matrix by a scalar into 'dst'.
+func ScaleTo(dst *mat.Dense, s float64, m mat.Matrix) *mat.Dense {
+	dst.Scale(s, m)
+	return dst
+}
+
 // Subtract computes the substraction of two matrices.
 func Subtract(m, n mat.Matrix) mat.Matrix {
 	r, c := m.Dims()
@@ -81,3 +219,9 @@ func Subtract(m, n mat.Matrix) mat.Matrix {
 	o.Sub(m, n)
 	return o
 }
+
+// SubtractTo computes the substraction of two matrices into 'dst'.
+func SubtractTo(dst *mat.Dense, m, n mat.Matrix) *mat.Dense {
+	dst.Sub(m, n)
+	return dst
+}
@@ -0,0 +1,119 @@
+package matrix_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"neuraldeep/utils/matrix"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gotest.tools/assert"
+)
+
+// TestGaussian ...
+func TestGaussian(t *testing.T) {
+	m := matrix.Gaussian(100, 100, 2, 0.5)
+	data := mat.DenseCopyOf(m).RawMatrix().Data
+	mean, stdDev := stat.MeanStdDev(data, nil)
+	assert.Assert(t, math.Abs(mean-2) < 0.05)
+	assert.Assert(t, math.Abs(stdDev-0.5) < 0.05)
+
+	// The same seed draws the same values
+	a := matrix.Gaussian(3, 4, 0, 1, rand.New(rand.NewSource(42)))
+	b := matrix.Gaussian(3, 4, 0, 1, rand.New(rand.NewSource(42)))
+	assert.Assert(t, mat.Equal(a, b))
+	assert.Assert(t, !mat.Equal(a, matrix.Gaussian(3, 4, 0, 1, rand.New(rand.NewSource(43)))))
+}
+
+// TestOrthogonal ...
+func TestOrthogonal(t *testing.T) {
+	for _, dims := range [][2]int{{3, 5}, {5, 3}, {4, 4}} {
+		m := matrix.Orthogonal(dims[0], dims[1], 1)
+		r, c := m.Dims()
+		assert.Equal(t, r, dims[0])
+		assert.Equal(t, c, dims[1])
+
+		// The smallest side is orthonormal
+		var p mat.Dense
+		if r < c {
+			p.Mul(m, m.T())
+		} else {
+			p.Mul(m.T(), m)
+		}
+		n, _ := p.Dims()
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				expected := 0.
+				if i == j {
+					expected = 1.
+				}
+				assert.Assert(t, math.Abs(p.At(i, j)-expected) < 1e-9)
+			}
+		}
+	}
+}
+
+// TestDestinationPassing ...
+func TestDestinationPassing(t *testing.T) {
+	m := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	n := mat.NewDense(2, 3, []float64{6, 5, 4, 3, 2, 1})
+	var dst mat.Dense
+	assert.Assert(t, mat.Equal(matrix.AddTo(&dst, m, n), matrix.Add(m, n)))
+	assert.Assert(t, mat.Equal(matrix.SubtractTo(&dst, m, n), matrix.Subtract(m, n)))
+	assert.Assert(t, mat.Equal(matrix.MultiplyTo(&dst, m, n), matrix.Multiply(m, n)))
+	assert.Assert(t, mat.Equal(matrix.ScaleTo(&dst, 2, m), matrix.Scale(2, m)))
+	assert.Assert(t, mat.Equal(matrix.AddScaledTo(&dst, m, 2, n), matrix.Add(m, matrix.Scale(2, n))))
+
+	// The destination can be an operand
+	sum := mat.DenseCopyOf(m)
+	matrix.AddScaledTo(sum, sum, -1, n)
+	assert.Assert(t, mat.Equal(sum, matrix.Subtract(m, n)))
+	x, y := mat.NewVecDense(2, []float64{1, 2}), mat.NewVecDense(3, []float64{1, 0, -1})
+	matrix.AddOuterTo(sum, sum, 1, x, y)
+	assert.Assert(t, mat.Equal(sum, matrix.Add(matrix.Subtract(m, n), matrix.Dot(x, y.T()))))
+
+	var product mat.VecDense
+	matrix.MulVecTo(&product, m, mat.NewVecDense(3, []float64{1, 1, 1}))
+	assert.DeepEqual(t, product.RawVector().Data, []float64{6, 15})
+	assert.DeepEqual(t, matrix.ColumnSums(m).RawMatrix().Data, []float64{5, 7, 9})
+}
+
+// BenchmarkHelpers compares the allocating helpers with their destination-passing variants on the matrices of a layer
+// of weights, ie. of 784 inputs and 30, 100 or 300 neurons.
+func BenchmarkHelpers(b *testing.B) {
+	sigmoid := func(_, _ int, v float64) float64 {
+		return 1 / (1 + math.Exp(-v))
+	}
+	for _, n := range []int{30, 100, 300} {
+		w, v := matrix.Gaussian(n, 784, 0, 1), matrix.Gaussian(n, 784, 0, 1)
+		a := matrix.Gaussian(784, 1, 0, 1)
+		for _, bench := range []struct {
+			name  string
+			alloc func()
+			reuse func(dst *mat.Dense)
+		}{
+			{"Add", func() { matrix.Add(w, v) }, func(dst *mat.Dense) { matrix.AddTo(dst, w, v) }},
+			{"Apply", func() { matrix.Apply(sigmoid, w) }, func(dst *mat.Dense) { matrix.ApplyTo(dst, sigmoid, w) }},
+			{"Dot", func() { matrix.Dot(w, a) }, func(dst *mat.Dense) { matrix.DotTo(dst, w, a) }},
+			{"Multiply", func() { matrix.Multiply(w, v) }, func(dst *mat.Dense) { matrix.MultiplyTo(dst, w, v) }},
+			{"Scale", func() { matrix.Scale(2, w) }, func(dst *mat.Dense) { matrix.ScaleTo(dst, 2, w) }},
+			{"Subtract", func() { matrix.Subtract(w, v) }, func(dst *mat.Dense) { matrix.SubtractTo(dst, w, v) }},
+		} {
+			b.Run(fmt.Sprintf("%s/%dx784", bench.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					bench.alloc()
+				}
+			})
+			b.Run(fmt.Sprintf("%sTo/%dx784", bench.name, n), func(b *testing.B) {
+				var dst mat.Dense
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					bench.reuse(&dst)
+				}
+			})
+		}
+	}
+}
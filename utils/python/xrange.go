@@ -1,13 +1,48 @@
 package python
 
-// XRange mimics Python's xrange providing a start index, a potentially included end index and an increment.
-func XRange(start, end, increment int) <-chan int {
-	c := make(chan int)
-	go func() {
-		for i := start; i <= end; i += increment {
-			c <- i
-		}
-		close(c)
-	}()
-	return c
+//--- TYPES
+
+// Range mimics Python's range: the integers from 'Start' included to 'Stop' excluded by steps of 'Step', which may be negative.
+// It is iterated over by index, which neither spawns a goroutine nor allocates, eg. `for k := range r.Len() { i := r.At(k) ... }`.
+type Range struct {
+	Start, Stop, Step int
+}
+
+//--- METHODS
+
+// At returns the value of index 'k' of the range, without checking that it is one.
+func (r Range) At(k int) int {
+	return r.Start + k*r.Step
+}
+
+// Len returns the number of values of the range, 0 if it's empty.
+func (r Range) Len() int {
+	switch {
+	case r.Step > 0 && r.Start < r.Stop:
+		return (r.Stop - r.Start + r.Step - 1) / r.Step
+	case r.Step < 0 && r.Start > r.Stop:
+		return (r.Start - r.Stop - r.Step - 1) / -r.Step
+	default:
+		return 0
+	}
+}
+
+// Slice returns the values of the range, like Python's `list(range(...))`.
+func (r Range) Slice() []int {
+	values := make([]int, r.Len())
+	for k := range values {
+		values[k] = r.At(k)
+	}
+	return values
+}
+
+//--- FUNCTIONS
+
+// XRange mimics Python's xrange providing a start index, an excluded end index and an increment.
+// As in Python, it panics if the increment is zero.
+func XRange(start, end, increment int) Range {
+	if increment == 0 {
+		panic("python: XRange() increment must not be zero")
+	}
+	return Range{Start: start, Stop: end, Step: increment}
 }
@@ -9,10 +9,38 @@ import (
 
 // TestXRange ...
 func TestXRange(t *testing.T) {
-	expected := []int{0, 2, 4, 6, 8}
-	var found []int
-	for v := range python.XRange(0, 8, 2) {
-		found = append(found, v)
+	for _, tt := range []struct {
+		start, end, increment int
+		expected              []int
+	}{
+		{0, 8, 2, []int{0, 2, 4, 6}},
+		{0, 9, 2, []int{0, 2, 4, 6, 8}},
+		{0, 10, 3, []int{0, 3, 6, 9}},
+		{2, 3, 1, []int{2}},
+		{5, 0, -2, []int{5, 3, 1}},
+		{3, 3, 1, []int{}},
+		{4, 0, 1, []int{}},
+		{0, 4, -1, []int{}},
+	} {
+		r := python.XRange(tt.start, tt.end, tt.increment)
+		assert.Equal(t, r.Len(), len(tt.expected))
+		assert.DeepEqual(t, r.Slice(), tt.expected)
+		found := []int{}
+		for k := range r.Len() {
+			found = append(found, r.At(k))
+		}
+		assert.DeepEqual(t, found, tt.expected)
 	}
-	assert.DeepEqual(t, found, expected)
+
+	assert.Assert(t, testing.AllocsPerRun(10, func() {
+		r := python.XRange(0, 1000, 7)
+		for k := range r.Len() {
+			_ = r.At(k)
+		}
+	}) == 0)
+
+	defer func() {
+		assert.Assert(t, recover() != nil)
+	}()
+	python.XRange(0, 1, 0)
 }
@@ -0,0 +1,258 @@
+package tensor
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/mat"
+)
+
+// gonum's mat package only handles float64 values: a Tensor is the single-precision counterpart of a mat.Dense,
+// relying on the float32 BLAS routines of gonum for the matrix products, and taking half the memory.
+// As in utils/matrix, the operations suffixed with `To` write their result into a passed destination, which lets
+// a training loop reuse its tensors from one mini-batch to the next.
+
+//--- TYPES
+
+// Tensor is a dense matrix of float32 values stored row by row.
+type Tensor struct {
+	Rows, Cols int
+	Data       []float32
+}
+
+//--- METHODS
+
+// Add adds the passed tensor of the same dimensions element-wise.
+func (t *Tensor) Add(o *Tensor) {
+	t.mustMatch(o)
+	blas32.Axpy(1, vector(o), vector(t))
+}
+
+// AddRow adds the 1×c 'row' to each row of the tensor, eg. the biases of a layer to the weighted inputs of a mini-batch.
+func (t *Tensor) AddRow(row *Tensor) {
+	if row.Rows != 1 || row.Cols != t.Cols {
+		panic(fmt.Sprintf("tensor: can't add a %dx%d row to a %dx%d tensor", row.Rows, row.Cols, t.Rows, t.Cols))
+	}
+	for i := 0; i < t.Rows; i++ {
+		r := t.Data[i*t.Cols : (i+1)*t.Cols]
+		for j, v := range row.Data {
+			r[j] += v
+		}
+	}
+}
+
+// AddScaled adds 'alpha' times the passed tensor of the same dimensions, ie. `t = t + αo`.
+func (t *Tensor) AddScaled(alpha float32, o *Tensor) {
+	t.mustMatch(o)
+	blas32.Axpy(alpha, vector(o), vector(t))
+}
+
+// Apply replaces each value 'v' of the tensor by `fn(v)`.
+func (t *Tensor) Apply(fn func(v float32) float32) {
+	for i, v := range t.Data {
+		t.Data[i] = fn(v)
+	}
+}
+
+// At returns the value of the i-th row and j-th column.
+func (t *Tensor) At(i, j int) float32 {
+	return t.Data[i*t.Cols+j]
+}
+
+// Clone returns a copy of the tensor.
+func (t *Tensor) Clone() *Tensor {
+	return New(t.Rows, t.Cols, append([]float32(nil), t.Data...))
+}
+
+// Copy copies the values of the passed tensor of the same dimensions.
+func (t *Tensor) Copy(o *Tensor) {
+	t.mustMatch(o)
+	copy(t.Data, o.Data)
+}
+
+// CopyMatrix copies the values of the passed matrix of the same dimensions, each one being rounded to the nearest float32.
+func (t *Tensor) CopyMatrix(m mat.Matrix) {
+	if r, c := m.Dims(); r != t.Rows || c != t.Cols {
+		panic(fmt.Sprintf("tensor: dimension mismatch: %dx%d and %dx%d", t.Rows, t.Cols, r, c))
+	}
+	if d, ok := m.(*mat.Dense); ok {
+		raw := d.RawMatrix()
+		for i := 0; i < t.Rows; i++ {
+			for j, v := range raw.Data[i*raw.Stride : i*raw.Stride+t.Cols] {
+				t.Data[i*t.Cols+j] = float32(v)
+			}
+		}
+		return
+	}
+	for i := 0; i < t.Rows; i++ {
+		for j := 0; j < t.Cols; j++ {
+			t.Data[i*t.Cols+j] = float32(m.At(i, j))
+		}
+	}
+}
+
+// Dims returns the number of rows and columns.
+func (t *Tensor) Dims() (r, c int) {
+	return t.Rows, t.Cols
+}
+
+// Matrix returns a float64 copy of the tensor.
+func (t *Tensor) Matrix() *mat.Dense {
+	data := make([]float64, len(t.Data))
+	for i, v := range t.Data {
+		data[i] = float64(v)
+	}
+	return mat.NewDense(t.Rows, t.Cols, data)
+}
+
+// MatrixTo writes the values of the tensor into 'dst', which must have the same dimensions, and returns it.
+func (t *Tensor) MatrixTo(dst *mat.Dense) *mat.Dense {
+	if r, c := dst.Dims(); r != t.Rows || c != t.Cols {
+		panic(fmt.Sprintf("tensor: dimension mismatch: %dx%d and %dx%d", t.Rows, t.Cols, r, c))
+	}
+	raw := dst.RawMatrix()
+	for i := 0; i < t.Rows; i++ {
+		row := raw.Data[i*raw.Stride : i*raw.Stride+t.Cols]
+		for j, v := range t.Data[i*t.Cols : (i+1)*t.Cols] {
+			row[j] = float64(v)
+		}
+	}
+	return dst
+}
+
+// MulElem multiplies the tensor element-wise by the passed one of the same dimensions.
+func (t *Tensor) MulElem(o *Tensor) {
+	t.mustMatch(o)
+	for i, v := range o.Data {
+		t.Data[i] *= v
+	}
+}
+
+// Resize changes the number of rows of the tensor, keeping its storage if it is large enough, eg. for the last
+// mini-batch of an epoch, which may be smaller than the others. The values are left as is and must be overwritten.
+func (t *Tensor) Resize(r int) {
+	if r*t.Cols > cap(t.Data) {
+		t.Data = make([]float32, r*t.Cols)
+	}
+	t.Rows, t.Data = r, t.Data[:r*t.Cols]
+}
+
+// Scale multiplies each value of the tensor by 'alpha'.
+func (t *Tensor) Scale(alpha float32) {
+	blas32.Scal(alpha, vector(t))
+}
+
+// Sub subtracts the passed tensor of the same dimensions element-wise.
+func (t *Tensor) Sub(o *Tensor) {
+	t.mustMatch(o)
+	blas32.Axpy(-1, vector(o), vector(t))
+}
+
+// SumRows returns the 1×c tensor of the sums of each column, ie. the sum of all the rows.
+func (t *Tensor) SumRows() *Tensor {
+	return t.SumRowsTo(New(1, t.Cols, nil))
+}
+
+// SumRowsTo writes the sums of each column into the 1×c tensor 'dst' and returns it.
+func (t *Tensor) SumRowsTo(dst *Tensor) *Tensor {
+	if dst.Rows != 1 || dst.Cols != t.Cols {
+		panic(fmt.Sprintf("tensor: can't sum the rows of a %dx%d tensor into a %dx%d one", t.Rows, t.Cols, dst.Rows, dst.Cols))
+	}
+	clear(dst.Data)
+	for i := 0; i < t.Rows; i++ {
+		for j, v := range t.Data[i*t.Cols : (i+1)*t.Cols] {
+			dst.Data[j] += v
+		}
+	}
+	return dst
+}
+
+func (t *Tensor) general() blas32.General {
+	return blas32.General{Rows: t.Rows, Cols: t.Cols, Stride: t.Cols, Data: t.Data}
+}
+
+func (t *Tensor) mustMatch(o *Tensor) {
+	if t.Rows != o.Rows || t.Cols != o.Cols {
+		panic(fmt.Sprintf("tensor: dimension mismatch: %dx%d and %dx%d", t.Rows, t.Cols, o.Rows, o.Cols))
+	}
+}
+
+//--- FUNCTIONS
+
+// New returns a tensor of 'r' rows and 'c' columns holding the passed row-major data, or zeros if 'data' is nil.
+func New(r, c int, data []float32) *Tensor {
+	if data == nil {
+		data = make([]float32, r*c)
+	}
+	if len(data) != r*c {
+		panic(fmt.Sprintf("tensor: %d values for a %dx%d tensor", len(data), r, c))
+	}
+	return &Tensor{Rows: r, Cols: c, Data: data}
+}
+
+// FromMatrix returns a float32 copy of the passed matrix, each value being rounded to the nearest float32.
+func FromMatrix(m mat.Matrix) *Tensor {
+	r, c := m.Dims()
+	t := New(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			t.Data[i*c+j] = float32(m.At(i, j))
+		}
+	}
+	return t
+}
+
+// Mul returns the matrix product `op(a)·op(b)`, where `op(x)` is the transpose of 'x' if the corresponding flag is set.
+func Mul(a *Tensor, transA bool, b *Tensor, transB bool) *Tensor {
+	r, _ := a.Dims()
+	if transA {
+		_, r = a.Dims()
+	}
+	c, _ := b.Dims()
+	if !transB {
+		_, c = b.Dims()
+	}
+	return MulTo(New(r, c, nil), a, transA, b, transB)
+}
+
+// MulTo writes the matrix product `op(a)·op(b)` into 'dst', which mustn't be one of them, and returns it.
+func MulTo(dst *Tensor, a *Tensor, transA bool, b *Tensor, transB bool) *Tensor {
+	r, k := a.Dims()
+	tA := blas.NoTrans
+	if transA {
+		r, k = k, r
+		tA = blas.Trans
+	}
+	k2, c := b.Dims()
+	tB := blas.NoTrans
+	if transB {
+		k2, c = c, k2
+		tB = blas.Trans
+	}
+	if k != k2 {
+		panic(fmt.Sprintf("tensor: can't multiply %dx%d by %dx%d", r, k, k2, c))
+	}
+	if dst.Rows != r || dst.Cols != c {
+		panic(fmt.Sprintf("tensor: can't write a %dx%d product into a %dx%d tensor", r, c, dst.Rows, dst.Cols))
+	}
+	if dst == a || dst == b {
+		panic("tensor: the destination of a product can't be one of its operands")
+	}
+	if r > 0 && c > 0 && k > 0 {
+		blas32.Gemm(tA, tB, 1, a.general(), b.general(), 0, dst.general())
+	} else {
+		clear(dst.Data)
+	}
+	return dst
+}
+
+// Sigmoid is the float32 sigmoid function.
+func Sigmoid(z float32) float32 {
+	return float32(1 / (1 + math.Exp(-float64(z))))
+}
+
+func vector(o *Tensor) blas32.Vector {
+	return blas32.Vector{N: len(o.Data), Inc: 1, Data: o.Data}
+}
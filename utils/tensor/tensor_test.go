@@ -0,0 +1,147 @@
+package tensor_test
+
+import (
+	"math"
+	"math/rand"
+	"neuraldeep/utils/tensor"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestMul ...
+func TestMul(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a, b := random(rng, 3, 5), random(rng, 5, 4)
+	for _, tt := range []struct {
+		name           string
+		a, b           *mat.Dense
+		transA, transB bool
+	}{
+		{"a·b", a, b, false, false},
+		{"aᵀ·b", mat.DenseCopyOf(a.T()), b, true, false},
+		{"a·bᵀ", a, mat.DenseCopyOf(b.T()), false, true},
+		{"aᵀ·bᵀ", mat.DenseCopyOf(a.T()), mat.DenseCopyOf(b.T()), true, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected mat.Dense
+			expected.Mul(a, b)
+			ta, tb := tensor.FromMatrix(tt.a), tensor.FromMatrix(tt.b)
+			assertClose(t, tensor.Mul(ta, tt.transA, tb, tt.transB).Matrix(), &expected)
+
+			dst := tensor.New(3, 4, nil)
+			dst.Data[0] = 42 // overwritten
+			tensor.MulTo(dst, ta, tt.transA, tb, tt.transB)
+			assertClose(t, dst.Matrix(), &expected)
+		})
+	}
+}
+
+// TestMulTo ...
+func TestMulTo(t *testing.T) {
+	a := tensor.New(2, 2, nil)
+	assert.Assert(t, panics(func() { tensor.MulTo(a, a, false, a, false) }))
+	assert.Assert(t, panics(func() { tensor.MulTo(tensor.New(2, 3, nil), a, false, a, false) }))
+	assert.Assert(t, panics(func() { tensor.Mul(tensor.New(2, 3, nil), false, a, false) }))
+}
+
+// TestElementWise ...
+func TestElementWise(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	a, b := random(rng, 3, 4), random(rng, 3, 4)
+
+	var expected mat.Dense
+	expected.Add(a, b)
+	sum := tensor.FromMatrix(a)
+	sum.Add(tensor.FromMatrix(b))
+	assertClose(t, sum.Matrix(), &expected)
+
+	expected.Sub(a, b)
+	diff := tensor.FromMatrix(a)
+	diff.Sub(tensor.FromMatrix(b))
+	assertClose(t, diff.Matrix(), &expected)
+
+	expected.MulElem(a, b)
+	prod := tensor.FromMatrix(a)
+	prod.MulElem(tensor.FromMatrix(b))
+	assertClose(t, prod.Matrix(), &expected)
+
+	var scaled mat.Dense
+	scaled.Scale(-0.5, b)
+	expected.Add(a, &scaled)
+	axpy := tensor.FromMatrix(a)
+	axpy.AddScaled(-0.5, tensor.FromMatrix(b))
+	assertClose(t, axpy.Matrix(), &expected)
+}
+
+// TestRows ...
+func TestRows(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	a, row := random(rng, 3, 4), random(rng, 1, 4)
+
+	var expected mat.Dense
+	expected.Mul(mat.NewDense(3, 1, []float64{1, 1, 1}), row)
+	expected.Add(&expected, a)
+	added := tensor.FromMatrix(a)
+	added.AddRow(tensor.FromMatrix(row))
+	assertClose(t, added.Matrix(), &expected)
+
+	var sums mat.Dense
+	sums.Mul(mat.NewDense(1, 3, []float64{1, 1, 1}), a)
+	dst := tensor.New(1, 4, []float32{1, 2, 3, 4}) // overwritten
+	assertClose(t, tensor.FromMatrix(a).SumRowsTo(dst).Matrix(), &sums)
+	assertClose(t, tensor.FromMatrix(a).SumRows().Matrix(), &sums)
+}
+
+// TestConversions ...
+func TestConversions(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	a := random(rng, 3, 4)
+	assertClose(t, tensor.FromMatrix(a).Matrix(), a)
+	assertClose(t, tensor.FromMatrix(a.T()).Matrix(), mat.DenseCopyOf(a.T()))
+
+	copied := tensor.New(3, 4, nil)
+	copied.CopyMatrix(a)
+	dst := mat.NewDense(3, 4, nil)
+	assert.Equal(t, copied.MatrixTo(dst), dst)
+	assertClose(t, dst, a)
+
+	// Shrinking keeps the storage, growing back beyond its capacity doesn't
+	data := copied.Data
+	copied.Resize(2)
+	assert.Equal(t, len(copied.Data), 8)
+	assert.Equal(t, &copied.Data[0], &data[0])
+	copied.Resize(5)
+	assert.Equal(t, len(copied.Data), 20)
+}
+
+// assertClose checks that both matrices have the same dimensions and values within the float32 precision.
+func assertClose(t *testing.T, actual, expected mat.Matrix) {
+	t.Helper()
+	r, c := expected.Dims()
+	ar, ac := actual.Dims()
+	assert.Equal(t, ar, r)
+	assert.Equal(t, ac, c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			assert.Assert(t, math.Abs(actual.At(i, j)-expected.At(i, j)) < 1e-5, "at (%d, %d): %g != %g", i, j, actual.At(i, j), expected.At(i, j))
+		}
+	}
+}
+
+func panics(fn func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	fn()
+	return
+}
+
+func random(rng *rand.Rand, r, c int) *mat.Dense {
+	data := make([]float64, r*c)
+	for i := range data {
+		data[i] = rng.NormFloat64()
+	}
+	return mat.NewDense(r, c, data)
+}
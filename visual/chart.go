@@ -0,0 +1,213 @@
+package visual
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"neuraldeep/network"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Line charts are written as SVG documents, which any browser can display and which keep the text crisp.
+
+const (
+	CHART_WIDTH  = 800
+	CHART_HEIGHT = 500
+
+	TRAINING_COLOR   = "#4472c4"
+	EVALUATION_COLOR = "#ed7d31"
+)
+
+//--- TYPES
+
+// Series is a named line of points sharing the same index in 'X' and 'Y'.
+type Series struct {
+	Name  string
+	Color string
+	X     []float64
+	Y     []float64
+}
+
+// LineChart draws its series over common axes. With 'Percent', the Y values are fractions displayed as percentages.
+type LineChart struct {
+	Title   string
+	XLabel  string
+	YLabel  string
+	Percent bool
+	Series  []Series
+}
+
+//--- METHODS
+
+// SaveSVG writes the chart to the SVG file at 'path', creating its folder if need be.
+func (c LineChart) SaveSVG(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = c.WriteSVG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteSVG renders the chart as an SVG document.
+func (c LineChart) WriteSVG(w io.Writer) error {
+	xMin, xMax, yMin, yMax, ok := c.bounds()
+	if !ok {
+		return errors.New("nothing to plot")
+	}
+	xStep := niceStep(xMax - xMin)
+	if xStep < 1 {
+		xStep = 1
+	}
+	yStep := niceStep(yMax - yMin)
+	xMin, xMax = math.Floor(xMin/xStep)*xStep, math.Ceil(xMax/xStep)*xStep
+	yMin, yMax = math.Floor(yMin/yStep)*yStep, math.Ceil(yMax/yStep)*yStep
+	if xMax == xMin {
+		xMax = xMin + xStep
+	}
+	if yMax == yMin {
+		yMax = yMin + yStep
+	}
+
+	const left, right, top, bottom = 80., 30., 50., 80.
+	plotW, plotH := CHART_WIDTH-left-right, CHART_HEIGHT-top-bottom
+	px := func(x float64) float64 { return left + (x-xMin)/(xMax-xMin)*plotW }
+	py := func(y float64) float64 { return top + plotH - (y-yMin)/(yMax-yMin)*plotH }
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", CHART_WIDTH, CHART_HEIGHT, CHART_WIDTH, CHART_HEIGHT)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(bw, `<text x="%.1f" y="30" text-anchor="middle" font-size="18" fill="#404040">%s</text>`+"\n", CHART_WIDTH/2., html.EscapeString(c.Title))
+
+	// Grid and ticks
+	for y := yMin; y <= yMax+yStep/2; y += yStep {
+		fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#d9d9d9"/>`+"\n", left, py(y), left+plotW, py(y))
+		fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" text-anchor="end" fill="#595959">%s</text>`+"\n", left-8, py(y)+4, c.formatY(y, yStep))
+	}
+	for x := xMin; x <= xMax+xStep/2; x += xStep {
+		fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#bfbfbf"/>`+"\n", px(x), top+plotH, px(x), top+plotH+5)
+		fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#595959">%s</text>`+"\n", px(x), top+plotH+20, formatNumber(x, xStep))
+	}
+	fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#bfbfbf"/>`+"\n", left, top+plotH, left+plotW, top+plotH)
+	fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#595959">%s</text>`+"\n", left+plotW/2, top+plotH+40, html.EscapeString(c.XLabel))
+	fmt.Fprintf(bw, `<text x="20" y="%.1f" text-anchor="middle" fill="#595959" transform="rotate(-90 20 %.1f)">%s</text>`+"\n", top+plotH/2, top+plotH/2, html.EscapeString(c.YLabel))
+
+	// Series and legend
+	legendX := left + plotW/2 - float64(len(c.Series))*60
+	for i, s := range c.Series {
+		var points []string
+		for j := range s.Y {
+			if j < len(s.X) && !math.IsNaN(s.Y[j]) {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", px(s.X[j]), py(s.Y[j])))
+			}
+		}
+		fmt.Fprintf(bw, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2.5"/>`+"\n", strings.Join(points, " "), s.Color)
+		if len(points) <= 60 {
+			for _, p := range points {
+				xy := strings.Split(p, ",")
+				fmt.Fprintf(bw, `<circle cx="%s" cy="%s" r="3.5" fill="%s"/>`+"\n", xy[0], xy[1], s.Color)
+			}
+		}
+		lx := legendX + float64(i)*120
+		ly := CHART_HEIGHT - 20.
+		fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="2.5"/>`+"\n", lx, ly-4, lx+24, ly-4, s.Color)
+		fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" fill="#595959">%s</text>`+"\n", lx+30, ly, html.EscapeString(s.Name))
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func (c LineChart) bounds() (xMin, xMax, yMin, yMax float64, ok bool) {
+	xMin, yMin = math.Inf(1), math.Inf(1)
+	xMax, yMax = math.Inf(-1), math.Inf(-1)
+	for _, s := range c.Series {
+		for j := range s.Y {
+			if j >= len(s.X) || math.IsNaN(s.Y[j]) {
+				continue
+			}
+			xMin, xMax = math.Min(xMin, s.X[j]), math.Max(xMax, s.X[j])
+			yMin, yMax = math.Min(yMin, s.Y[j]), math.Max(yMax, s.Y[j])
+			ok = true
+		}
+	}
+	return
+}
+
+func (c LineChart) formatY(y, step float64) string {
+	if c.Percent {
+		return formatNumber(y*100, step*100) + "%"
+	}
+	return formatNumber(y, step)
+}
+
+//--- FUNCTIONS
+
+// TrainingCharts returns the chart of the costs and the chart of the accuracies per epoch of the passed training history,
+// each holding a line for the training data and one for the evaluation data if they were monitored.
+func TrainingCharts(history []network.Epoch, title string) (cost, accuracy LineChart) {
+	cost = LineChart{Title: title + " - cost", XLabel: "epoch", YLabel: "cost"}
+	accuracy = LineChart{Title: title + " - accuracy", XLabel: "epoch", YLabel: "accuracy", Percent: true}
+	series := func(name, color string, value func(e network.Epoch) *float64) (s Series) {
+		s = Series{Name: name, Color: color}
+		for _, e := range history {
+			if v := value(e); v != nil {
+				s.X = append(s.X, float64(e.Epoch))
+				s.Y = append(s.Y, *v)
+			}
+		}
+		return
+	}
+	for _, s := range []Series{
+		series("training", TRAINING_COLOR, func(e network.Epoch) *float64 { return e.TrainingCost }),
+		series("evaluation", EVALUATION_COLOR, func(e network.Epoch) *float64 { return e.EvaluationCost }),
+	} {
+		if len(s.Y) > 0 {
+			cost.Series = append(cost.Series, s)
+		}
+	}
+	for _, s := range []Series{
+		series("training", TRAINING_COLOR, func(e network.Epoch) *float64 { return e.TrainingAccuracy }),
+		series("evaluation", EVALUATION_COLOR, func(e network.Epoch) *float64 { return e.EvaluationAccuracy }),
+	} {
+		if len(s.Y) > 0 {
+			accuracy.Series = append(accuracy.Series, s)
+		}
+	}
+	return
+}
+
+// formatNumber prints 'v' with as many decimals as the 'step' between two ticks requires.
+func formatNumber(v, step float64) string {
+	decimals := 0
+	if step > 0 && step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// niceStep returns a step of 1, 2 or 5 times a power of ten splitting the 'span' into about 5 to 10 ticks.
+func niceStep(span float64) float64 {
+	if span <= 0 || math.IsInf(span, 0) || math.IsNaN(span) {
+		return 1
+	}
+	raw := span / 6
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, f := range []float64{1, 2, 5} {
+		if raw <= f*magnitude {
+			return f * magnitude
+		}
+	}
+	return 10 * magnitude
+}
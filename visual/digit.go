@@ -0,0 +1,192 @@
+package visual
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"  // register the GIF decoder
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+	"io"
+	"math"
+	"os"
+)
+
+// A package to turn images into network inputs and network data into images.
+// Digits are prepared the way the NIST images were to build the MNIST database: the digit is size-normalized
+// to fit in a 20×20 box while preserving its aspect ratio, then centered by its center of mass in a 28×28 image,
+// with the ink in white (255) over a black (0) background.
+
+const (
+	MNIST_SIDE = 28
+	MNIST_BOX  = 20
+
+	// inkThreshold is the fraction of the strongest ink under which a pixel is considered blank when cropping
+	inkThreshold = 0.1
+)
+
+//--- FUNCTIONS
+
+// DecodeDigit reads a PNG, JPEG or GIF image of a single digit into the 784 pixels of an MNIST-like input.
+func DecodeDigit(r io.Reader) ([]float64, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return Digitize(img)
+}
+
+// Digitize converts the passed image of a single digit into the 784 pixels of an MNIST-like input:
+// it is converted to grayscale, inverted if the digit is darker than its background, cropped to the bounding box
+// of the ink, resized to fit in a 20×20 box and centered by its center of mass in a 28×28 image.
+func Digitize(img image.Image) ([]float64, error) {
+	ink, w, h := grayscale(img)
+	if w == 0 || h == 0 {
+		return nil, errors.New("empty image")
+	}
+	removeBackground(ink, w, h)
+
+	// Crop to the bounding box of the ink
+	x0, y0, x1, y1, ok := boundingBox(ink, w, h)
+	if !ok {
+		return nil, errors.New("no digit found in the image")
+	}
+	cw, ch := x1-x0, y1-y0
+
+	// Resize so that the longest side fits the box
+	scale := float64(MNIST_BOX) / math.Max(float64(cw), float64(ch))
+	bw := int(math.Max(1, math.Round(float64(cw)*scale)))
+	bh := int(math.Max(1, math.Round(float64(ch)*scale)))
+	box := resize(ink, w, x0, y0, cw, ch, bw, bh)
+
+	// Center by the center of mass
+	var mass, cx, cy float64
+	for y := 0; y < bh; y++ {
+		for x := 0; x < bw; x++ {
+			v := box[y*bw+x]
+			mass += v
+			cx += v * (float64(x) + 0.5)
+			cy += v * (float64(y) + 0.5)
+		}
+	}
+	if mass == 0 {
+		return nil, errors.New("no digit found in the image")
+	}
+	cx, cy = cx/mass, cy/mass
+	dx := int(math.Round(MNIST_SIDE/2 - cx))
+	dy := int(math.Round(MNIST_SIDE/2 - cy))
+	digit := make([]float64, MNIST_SIDE*MNIST_SIDE)
+	for y := 0; y < bh; y++ {
+		for x := 0; x < bw; x++ {
+			tx, ty := x+dx, y+dy
+			if tx >= 0 && tx < MNIST_SIDE && ty >= 0 && ty < MNIST_SIDE {
+				digit[ty*MNIST_SIDE+tx] = math.Round(box[y*bw+x])
+			}
+		}
+	}
+	return digit, nil
+}
+
+// ReadDigit reads the image file at 'path' into the 784 pixels of an MNIST-like input.
+func ReadDigit(path string) ([]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeDigit(f)
+}
+
+// boundingBox returns the smallest rectangle [x0, x1[ × [y0, y1[ holding all the pixels with a significant amount of ink.
+func boundingBox(ink []float64, w, h int) (x0, y0, x1, y1 int, ok bool) {
+	peak := 0.
+	for _, v := range ink {
+		peak = math.Max(peak, v)
+	}
+	if peak == 0 {
+		return
+	}
+	x0, y0 = w, h
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if ink[y*w+x] > inkThreshold*peak {
+				x0, y0 = min(x0, x), min(y0, y)
+				x1, y1 = max(x1, x+1), max(y1, y+1)
+			}
+		}
+	}
+	return x0, y0, x1, y1, true
+}
+
+// grayscale returns the luminance of each pixel of the image in the [0, 255] range, row by row.
+func grayscale(img image.Image) (gray []float64, w, h int) {
+	bounds := img.Bounds()
+	w, h = bounds.Dx(), bounds.Dy()
+	gray = make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+			_, _, _, a := c.RGBA()
+			// Colors are alpha-premultiplied: blending with white makes the transparent pixels part of the background
+			v := float64(color.Gray16Model.Convert(c).(color.Gray16).Y) + float64(0xffff-a)
+			gray[y*w+x] = math.Min(v/0xffff*255, 255)
+		}
+	}
+	return
+}
+
+// removeBackground turns the grayscale pixels into ink intensities: the image is inverted if its border is light,
+// eg. a photo of a dark digit on paper, and the level of the border is subtracted before stretching to [0, 255].
+func removeBackground(gray []float64, w, h int) {
+	var border float64
+	var n int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x == 0 || y == 0 || x == w-1 || y == h-1 {
+				border += gray[y*w+x]
+				n++
+			}
+		}
+	}
+	background := border / float64(n)
+	if background > 127 {
+		for i, v := range gray {
+			gray[i] = 255 - v
+		}
+		background = 255 - background
+	}
+	peak := 0.
+	for _, v := range gray {
+		peak = math.Max(peak, v)
+	}
+	for i, v := range gray {
+		if peak <= background {
+			gray[i] = 0
+		} else {
+			gray[i] = math.Max(0, (v-background)/(peak-background)*255)
+		}
+	}
+}
+
+// resize scales the 'cw'×'ch' area at ('x0', 'y0') of the image of width 'w' to 'dw'×'dh' pixels,
+// each destination pixel being the mean of the source area it covers (anti-aliasing).
+func resize(src []float64, w, x0, y0, cw, ch, dw, dh int) []float64 {
+	dst := make([]float64, dw*dh)
+	// Supersample each destination pixel with enough points to cover every source pixel
+	sx := max(1, int(math.Ceil(float64(cw)/float64(dw))))*2 + 1
+	sy := max(1, int(math.Ceil(float64(ch)/float64(dh))))*2 + 1
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sum := 0.
+			for j := 0; j < sy; j++ {
+				for i := 0; i < sx; i++ {
+					px := int((float64(x) + (float64(i)+0.5)/float64(sx)) * float64(cw) / float64(dw))
+					py := int((float64(y) + (float64(j)+0.5)/float64(sy)) * float64(ch) / float64(dh))
+					sum += src[(y0+min(py, ch-1))*w+x0+min(px, cw-1)]
+				}
+			}
+			dst[y*dw+x] = sum / float64(sx*sy)
+		}
+	}
+	return dst
+}
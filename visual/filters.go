@@ -0,0 +1,91 @@
+package visual
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// The incoming weights of a neuron whose layer follows a square one, eg. the 784 pixels of an MNIST digit,
+// can be drawn as a heat-map showing which part of the image excites (in red) or inhibits (in blue) the neuron.
+
+//--- FUNCTIONS
+
+// Filters tiles the heat-maps of the incoming weights of each neuron, ie. each row of the 'weights' matrix, 'columns' per row.
+// Each heat-map is normalized by its own largest absolute weight, white standing for zero.
+func Filters(weights mat.Matrix, scale, columns int) (*image.RGBA, error) {
+	r, c := weights.Dims()
+	side := int(math.Round(math.Sqrt(float64(c))))
+	if side*side != c {
+		return nil, fmt.Errorf("the input dimension %d is not a perfect square", c)
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(r))))
+	}
+	columns = min(columns, r)
+	const margin = 2
+	cell := side*scale + margin
+	rows := (r + columns - 1) / columns
+	grid := image.NewRGBA(image.Rect(0, 0, columns*cell+margin, rows*cell+margin))
+	draw.Draw(grid, grid.Bounds(), &image.Uniform{C: color.RGBA{R: 64, G: 64, B: 64, A: 255}}, image.Point{}, draw.Src)
+	row := make([]float64, c)
+	for i := 0; i < r; i++ {
+		bound := 0.
+		for j := 0; j < c; j++ {
+			row[j] = weights.At(i, j)
+			bound = math.Max(bound, math.Abs(row[j]))
+		}
+		heatMap := HeatMap(row, side, scale, bound)
+		x, y := (i%columns)*cell+margin, (i/columns)*cell+margin
+		draw.Draw(grid, image.Rect(x, y, x+side*scale, y+side*scale), heatMap, image.Point{}, draw.Src)
+	}
+	return grid, nil
+}
+
+// HeatMap draws the flattened 'side'×'side' values with a diverging palette going from blue for `-bound` to red for `bound`
+// through white for zero, each value being drawn as a 'scale'×'scale' square.
+func HeatMap(values []float64, side, scale int, bound float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, side*scale, side*scale))
+	for i, v := range values {
+		c := Diverging(v, bound)
+		x, y := (i%side)*scale, (i/side)*scale
+		for sy := 0; sy < scale; sy++ {
+			for sx := 0; sx < scale; sx++ {
+				img.SetRGBA(x+sx, y+sy, c)
+			}
+		}
+	}
+	return img
+}
+
+// Diverging returns the color of 'v' on a blue-white-red scale clipped to [-bound, bound].
+func Diverging(v, bound float64) color.RGBA {
+	t := 0.
+	if bound > 0 {
+		t = math.Max(-1, math.Min(1, v/bound))
+	}
+	fade := uint8(math.Round(255 * (1 - math.Abs(t))))
+	if t >= 0 {
+		return color.RGBA{R: 255, G: fade, B: fade, A: 255}
+	}
+	return color.RGBA{R: fade, G: fade, B: 255, A: 255}
+}
+
+// SquareLayers returns the indices of the weight matrices whose input dimension is a perfect square, ie. the ones Filters() can draw.
+func SquareLayers(weights []mat.Matrix) (layers []int) {
+	for l, w := range weights {
+		_, c := w.Dims()
+		side := int(math.Round(math.Sqrt(float64(c))))
+		if side > 1 && side*side == c {
+			layers = append(layers, l)
+		}
+	}
+	return
+}
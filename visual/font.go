@@ -0,0 +1,66 @@
+package visual
+
+import (
+	"image"
+	"image/color"
+)
+
+// A minimal 3×5 bitmap font to annotate images without any external font package.
+
+const (
+	GLYPH_WIDTH  = 3
+	GLYPH_HEIGHT = 5
+)
+
+var glyphs = map[rune][GLYPH_HEIGHT]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+	'.': {"...", "...", "...", "...", ".#."},
+	'>': {"#..", ".#.", "..#", ".#.", "#.."},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+	'e': {"...", "###", "##.", "#..", "###"},
+	' ': {"...", "...", "...", "...", "..."},
+}
+
+//--- FUNCTIONS
+
+// DrawText writes the passed text at ('x', 'y') with glyphs scaled by 'scale', leaving one scaled pixel between characters.
+// Unknown characters are drawn as blanks.
+func DrawText(img *image.RGBA, x, y int, text string, c color.Color, scale int) {
+	for _, r := range text {
+		glyph, ok := glyphs[r]
+		if ok {
+			for gy, line := range glyph {
+				for gx, pixel := range line {
+					if pixel != '#' {
+						continue
+					}
+					for sy := 0; sy < scale; sy++ {
+						for sx := 0; sx < scale; sx++ {
+							img.Set(x+gx*scale+sx, y+gy*scale+sy, c)
+						}
+					}
+				}
+			}
+		}
+		x += (GLYPH_WIDTH + 1) * scale
+	}
+}
+
+// TextWidth returns the width in pixels of the passed text drawn with DrawText().
+func TextWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*(GLYPH_WIDTH+1) - 1) * scale
+}
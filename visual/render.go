@@ -0,0 +1,136 @@
+package visual
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"neuraldeep/network"
+	"os"
+	"path/filepath"
+)
+
+// Inputs are rendered as square grayscale images, their values being expected in the [0, 255] range of the MNIST pixels.
+
+const (
+	SHEET_COLUMNS = 20
+	TILE_SCALE    = 2
+)
+
+var (
+	BACKGROUND = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	EXPECTED   = color.RGBA{R: 0, G: 140, B: 0, A: 255}
+	PREDICTED  = color.RGBA{R: 200, G: 0, B: 0, A: 255}
+)
+
+//--- FUNCTIONS
+
+// ContactSheet tiles the misclassified inputs row by row, 'columns' per row, each one annotated with its true label
+// in green and the predicted one in red, eg. "4>9" for a 4 taken for a 9.
+func ContactSheet(errs []network.Misclassification, columns int) (*image.RGBA, error) {
+	if len(errs) == 0 {
+		return nil, errors.New("no misclassified input")
+	}
+	if columns <= 0 {
+		columns = SHEET_COLUMNS
+	}
+	if len(errs) < columns {
+		columns = len(errs)
+	}
+	side, err := sideOf(errs[0].Input.Data)
+	if err != nil {
+		return nil, err
+	}
+	const margin = 4
+	textScale := TILE_SCALE
+	cellW := side*TILE_SCALE + 2*margin
+	cellH := side*TILE_SCALE + 2*margin + (GLYPH_HEIGHT+2)*textScale
+	rows := (len(errs) + columns - 1) / columns
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*cellW, rows*cellH))
+	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{C: BACKGROUND}, image.Point{}, draw.Src)
+	for i, e := range errs {
+		tile, err := Tile(e.Input.Data, TILE_SCALE)
+		if err != nil {
+			return nil, fmt.Errorf("input #%d: %w", e.Index, err)
+		}
+		x, y := (i%columns)*cellW+margin, (i/columns)*cellH+margin
+		draw.Draw(sheet, image.Rect(x, y, x+side*TILE_SCALE, y+side*TILE_SCALE), tile, image.Point{}, draw.Src)
+		expected := fmt.Sprintf("%d", int(math.Round(e.Input.Label.Value)))
+		predicted := fmt.Sprintf("%d", e.Predicted)
+		ty := y + side*TILE_SCALE + textScale
+		tx := x + (side*TILE_SCALE-TextWidth(expected+">"+predicted, textScale))/2
+		DrawText(sheet, tx, ty, expected, EXPECTED, textScale)
+		tx += TextWidth(expected, textScale) + textScale
+		DrawText(sheet, tx, ty, ">", color.Black, textScale)
+		tx += TextWidth(">", textScale) + textScale
+		DrawText(sheet, tx, ty, predicted, PREDICTED, textScale)
+	}
+	return sheet, nil
+}
+
+// SavePNG encodes the image to the PNG file at 'path', creating its folder if need be.
+func SavePNG(img image.Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Tile renders the flattened square image 'data' in grayscale, each pixel being drawn as a 'scale'×'scale' square.
+func Tile(data []float64, scale int) (*image.Gray, error) {
+	side, err := sideOf(data)
+	if err != nil {
+		return nil, err
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewGray(image.Rect(0, 0, side*scale, side*scale))
+	for i, v := range data {
+		x, y := (i%side)*scale, (i/side)*scale
+		g := color.Gray{Y: uint8(math.Round(math.Max(0, math.Min(255, v))))}
+		for sy := 0; sy < scale; sy++ {
+			for sx := 0; sx < scale; sx++ {
+				img.SetGray(x+sx, y+sy, g)
+			}
+		}
+	}
+	return img, nil
+}
+
+// WriteTiles saves each input of the dataset as a PNG file in the 'dir' folder, named after its index and label, eg. `00042_7.png`.
+func WriteTiles(ds network.Dataset, dir string, scale int) error {
+	for i, input := range ds {
+		tile, err := Tile(input.Data, scale)
+		if err != nil {
+			return fmt.Errorf("input #%d: %w", i, err)
+		}
+		name := fmt.Sprintf("%05d.png", i)
+		if input.Label != nil {
+			name = fmt.Sprintf("%05d_%d.png", i, int(math.Round(input.Label.Value)))
+		}
+		if err := SavePNG(tile, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sideOf(data []float64) (int, error) {
+	side := int(math.Round(math.Sqrt(float64(len(data)))))
+	if side == 0 || side*side != len(data) {
+		return 0, fmt.Errorf("not a square image: %d values", len(data))
+	}
+	return side, nil
+}
@@ -0,0 +1,139 @@
+package visual_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"neuraldeep/network"
+	"neuraldeep/visual"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gotest.tools/assert"
+)
+
+// TestDecodeDigit ...
+func TestDecodeDigit(t *testing.T) {
+	// A dark vertical stroke in the top left corner of a light 100×80 photo
+	img := image.NewGray(image.Rect(0, 0, 100, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 100; x++ {
+			img.SetGray(x, y, color.Gray{Y: 230})
+			if x >= 10 && x < 20 && y >= 5 && y < 45 {
+				img.SetGray(x, y, color.Gray{Y: 20})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	digit, err := visual.DecodeDigit(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(digit), 784)
+
+	// The stroke fills the 20 pixels high box and is centered on the image
+	var mass, cx, cy float64
+	rows := map[int]bool{}
+	for i, v := range digit {
+		assert.Assert(t, v >= 0 && v <= 255)
+		if v > 0 {
+			rows[i/28] = true
+		}
+		mass += v
+		cx += v * (float64(i%28) + 0.5)
+		cy += v * (float64(i/28) + 0.5)
+	}
+	assert.Equal(t, len(rows), 20)
+	assert.Assert(t, cx/mass > 13 && cx/mass < 15)
+	assert.Assert(t, cy/mass > 13 && cy/mass < 15)
+	assert.Equal(t, digit[0], 0.)
+	assert.Equal(t, digit[14*28+14], 255.)
+
+	_, err = visual.Digitize(image.NewGray(image.Rect(0, 0, 10, 10)))
+	assert.Error(t, err, "no digit found in the image")
+}
+
+// TestContactSheet ...
+func TestContactSheet(t *testing.T) {
+	data := make([]float64, 16)
+	data[5] = 255
+	errs := []network.Misclassification{
+		{Index: 0, Input: &network.Input{Data: data, Label: network.ToLabel(4, 10)}, Predicted: 9},
+		{Index: 3, Input: &network.Input{Data: data, Label: network.ToLabel(7, 10)}, Predicted: 1},
+		{Index: 8, Input: &network.Input{Data: data, Label: network.ToLabel(3, 10)}, Predicted: 8},
+	}
+	sheet, err := visual.ContactSheet(errs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tile, err := visual.Tile(data, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tile.Bounds().Dx(), 8)
+	assert.Equal(t, tile.GrayAt(2, 2).Y, uint8(255))
+	assert.Equal(t, tile.GrayAt(0, 0).Y, uint8(0))
+	// 2 columns × 2 rows of cells holding a tile with margins and a line of text
+	assert.Equal(t, sheet.Bounds().Dx(), 2*(8+8))
+	assert.Equal(t, sheet.Bounds().Dy(), 2*(8+8+7*2))
+	annotated := false
+	for y := 0; y < sheet.Bounds().Dy(); y++ {
+		for x := 0; x < sheet.Bounds().Dx(); x++ {
+			if sheet.RGBAAt(x, y) == visual.EXPECTED {
+				annotated = true
+			}
+		}
+	}
+	assert.Assert(t, annotated)
+
+	_, err = visual.Tile(make([]float64, 10), 1)
+	assert.Error(t, err, "not a square image: 10 values")
+}
+
+// TestFilters ...
+func TestFilters(t *testing.T) {
+	weights := []mat.Matrix{
+		mat.NewDense(3, 4, []float64{1, -1, 0, 0.5, 0, 0, 0, 0, 2, 2, 2, -2}),
+		mat.NewDense(2, 3, nil),
+	}
+	assert.DeepEqual(t, visual.SquareLayers(weights), []int{0})
+	grid, err := visual.Filters(weights[0], 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 filters of 2×2 pixels separated by 2 pixels wide margins
+	assert.Equal(t, grid.Bounds().Dx(), 3*(2+2)+2)
+	assert.Equal(t, grid.Bounds().Dy(), 2+2+2)
+	assert.Equal(t, grid.RGBAAt(2, 2), color.RGBA{R: 255, G: 0, B: 0, A: 255})
+	assert.Equal(t, grid.RGBAAt(3, 2), color.RGBA{R: 0, G: 0, B: 255, A: 255})
+	assert.Equal(t, grid.RGBAAt(6, 2), color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	_, err = visual.Filters(weights[1], 1, 0)
+	assert.Error(t, err, "the input dimension 3 is not a perfect square")
+}
+
+// TestTrainingCharts ...
+func TestTrainingCharts(t *testing.T) {
+	history := network.History([]float64{0.6, 0.4, 0.35}, []int{85, 90, 92}, nil, nil, 0, 100)
+	cost, accuracy := visual.TrainingCharts(history, "Network 2")
+	assert.Equal(t, len(cost.Series), 1)
+	assert.Equal(t, cost.Series[0].Name, "evaluation")
+	assert.DeepEqual(t, accuracy.Series[0].Y, []float64{0.85, 0.9, 0.92})
+
+	var buf bytes.Buffer
+	if err := accuracy.WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	assert.Assert(t, strings.HasPrefix(svg, "<svg"))
+	assert.Assert(t, strings.Contains(svg, "Network 2 - accuracy"))
+	assert.Assert(t, strings.Contains(svg, "90%"))
+	assert.Equal(t, strings.Count(svg, "<polyline"), 1)
+
+	assert.Error(t, visual.LineChart{}.WriteSVG(&buf), "nothing to plot")
+}